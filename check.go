@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wozniakjan/fsp2/fsp"
+)
+
+// check verifies a solution file against a problem file, "fsp2 check
+// INPUT SOLUTION". It exits with 0 for a feasible tour and 3 for an
+// infeasible one.
+func check(args []string) {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	lenient := flags.Bool("lenient", false, "skip malformed flight lines of the input")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: fsp2 check [-lenient] INPUT SOLUTION")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	in, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	defer in.Close()
+	problem, err := fsp.ReadInput(in, *lenient)
+	if err != nil {
+		fail(fmt.Errorf("%v: %v", flags.Arg(0), err))
+	}
+	sf, err := os.Open(flags.Arg(1))
+	if err != nil {
+		fail(err)
+	}
+	defer sf.Close()
+	s, err := fsp.ReadSolution(sf, problem)
+	if err != nil {
+		fail(fmt.Errorf("%v: %v", flags.Arg(1), err))
+	}
+
+	// the total is what the legs cost in the input, a phantom leg costs
+	// nothing as it has no price there
+	total := fsp.Money(0)
+	for _, f := range s.Flights {
+		if price, ok := problem.InputPrice(f); ok {
+			total += price
+		}
+	}
+	violations := fsp.Validate(problem, s)
+	for _, v := range violations {
+		fmt.Println(v)
+	}
+	fmt.Println("total cost", total)
+	if len(violations) > 0 {
+		fmt.Println("infeasible")
+		os.Exit(3)
+	}
+	fmt.Println("feasible")
+}
@@ -0,0 +1,173 @@
+package fsp
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+)
+
+/*****************************************************************************/
+/* Beam search                                                               */
+/*****************************************************************************/
+
+// DefaultBeamWidth is the beam width NewBeam uses when given none.
+const DefaultBeamWidth = 256
+
+// Beam builds a tour day by day keeping only the width partial tours with
+// the lowest cost plus an estimate of the rest. The estimate is the
+// cheapest flight out of the last city on the next day plus the cheapest
+// flight of every later day, partial tours whose last city has no flight
+// the next day are dropped right away.
+type Beam struct {
+	problem *Problem
+	width   int
+}
+
+func NewBeam(problem *Problem, width int) *Beam {
+	if width <= 0 {
+		width = DefaultBeamWidth
+	}
+	return &Beam{problem: problem, width: width}
+}
+
+// beamNode is a partial tour, its earlier flights are found through the
+// parent index into the previous layer
+type beamNode struct {
+	parent int32
+	flight *Flight
+	cost   Money
+	key    Money  // cost plus the estimate of the rest of the tour
+	state  uint64 // hash of the visited areas
+}
+
+// rest returns for every day the sum of the cheapest flights of that day
+// and all later days of the trip
+func (b *Beam) rest() []Money {
+	p := b.problem
+	g := &p.indices.fromDayTo
+	cheapest := make([]Money, p.length+2)
+	for d := range cheapest {
+		cheapest[d] = unreachable
+	}
+	for i := range g.flights {
+		if f := &g.flights[i]; f.Cost < cheapest[f.Day] {
+			cheapest[f.Day] = f.Cost
+		}
+	}
+	rest := make([]Money, p.length+2)
+	for d := p.length; d >= 1; d-- {
+		if cheapest[d] == unreachable {
+			// no tour at all, leave the estimate to the next flight
+			cheapest[d] = 0
+		}
+		rest[d] = rest[d+1] + cheapest[d]
+	}
+	return rest
+}
+
+// maxBeamWidth bounds how far Solve widens a beam that ran empty
+const maxBeamWidth = 1 << 16
+
+// Solve sends the best tour of the beam, when the beam runs empty it tries
+// again four times wider.
+func (b *Beam) Solve(ctx context.Context, comm Comm) {
+	for width := b.width; width <= maxBeamWidth && ctx.Err() == nil; width *= 4 {
+		if s, ok := b.search(ctx, width); ok {
+			comm.Send(s)
+			return
+		}
+	}
+}
+
+// search returns the cheapest complete tour left in a beam of the width,
+// false when the beam ran empty or ctx was done
+func (b *Beam) search(ctx context.Context, width int) (Solution, bool) {
+	p := b.problem
+	g := &p.indices.fromDayTo
+	rest := b.rest()
+	words := (len(p.areaLookup.indexToName) + 63) / 64
+	// partial tours in the same city having visited the same areas only
+	// differ in cost, the beam keeps the cheapest of them
+	rng := rand.New(rand.NewSource(1))
+	areaHash := make([]uint64, len(p.areaLookup.indexToName))
+	for a := range areaHash {
+		areaHash[a] = rng.Uint64()
+	}
+	cityHash := make([]uint64, len(p.cityLookup.indexToName))
+	for c := range cityHash {
+		cityHash[c] = rng.Uint64()
+	}
+	layers := make([][]beamNode, p.length+1)
+	layers[0] = []beamNode{{parent: -1}}
+	visited := make([]uint64, words) // of the nodes of the previous layer
+	for day := 1; day <= p.length; day++ {
+		if ctx.Err() != nil {
+			return Solution{}, false
+		}
+		var next []beamNode
+		for k, n := range layers[day-1] {
+			from := p.start
+			if n.flight != nil {
+				from = n.flight.To
+			}
+			bits := visited[k*words : (k+1)*words]
+			dst := g.departures(from, Day(day))
+			for i := range dst {
+				f := &dst[i]
+				if day == p.length {
+					if f.ToArea != p.goal {
+						continue
+					}
+				} else if f.ToArea == p.goal || bits[f.ToArea/64]&(1<<(f.ToArea%64)) != 0 {
+					continue
+				}
+				cost := n.cost + f.Cost
+				key := cost
+				if day < p.length {
+					out := g.departures(f.To, Day(day+1))
+					if len(out) == 0 {
+						continue
+					}
+					key += out[0].Cost + rest[day+2]
+				}
+				visits := n.state ^ areaHash[f.ToArea]
+				next = append(next, beamNode{int32(k), f, cost, key, visits})
+			}
+		}
+		if len(next) == 0 {
+			logln(Verbose, "beam ran empty on day", day)
+			return Solution{}, false
+		}
+		sort.Slice(next, func(i, j int) bool {
+			if next[i].key != next[j].key {
+				return next[i].key < next[j].key
+			}
+			return next[i].cost < next[j].cost
+		})
+		seen := make(map[uint64]bool, min(width, len(next)))
+		kept := next[:0]
+		for _, n := range next {
+			if len(kept) == width {
+				break
+			}
+			if state := n.state ^ cityHash[n.flight.To]; !seen[state] {
+				seen[state] = true
+				kept = append(kept, n)
+			}
+		}
+		next = kept
+		nextVisited := make([]uint64, len(next)*words)
+		for k, n := range next {
+			bits := nextVisited[k*words : (k+1)*words]
+			copy(bits, visited[int(n.parent)*words:])
+			bits[n.flight.ToArea/64] |= 1 << (n.flight.ToArea % 64)
+		}
+		layers[day], visited = next, nextVisited
+	}
+	flights := make([]*Flight, p.length)
+	for day, k := p.length, int32(0); day >= 1; day-- {
+		n := layers[day][k]
+		flights[day-1], k = n.flight, n.parent
+	}
+	return NewSolution(flights), true
+}
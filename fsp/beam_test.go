@@ -0,0 +1,49 @@
+package fsp
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func TestBeamOracle(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for ti := 0; ti < 100; ti++ {
+		input := randomInput(rng, 2+rng.Intn(5))
+		problem, err := ReadInput(bytes.NewReader([]byte(input)), false)
+		if err != nil {
+			t.Fatal(ti, err)
+		}
+		exact, ok := NewExact(problem).Optimum(context.Background())
+		// a beam wide enough to hold every partial tour is exhaustive
+		s, found := NewBeam(problem, 0).search(context.Background(), 1<<20)
+		if found != ok {
+			t.Fatal(ti, "feasibility mismatch, exact", ok, "\n", input)
+		}
+		if ok && s.TotalCost != exact.TotalCost {
+			t.Fatal(ti, "beam", s.TotalCost, "!= exact", exact.TotalCost, "\n", input)
+		}
+	}
+}
+
+func TestBeam(t *testing.T) {
+	in, err := os.Open("../data/2.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	problem, err := ReadInput(in, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, width := range []int{1, 16, DefaultBeamWidth} {
+		c := &testcomm{}
+		NewBeam(problem, width).Solve(context.Background(), c)
+		if vs := Validate(problem, c.solution); len(vs) > 0 {
+			t.Fatal("width", width, "invalid beam tour", vs)
+		}
+		t.Log("width", width, "cost", c.solution.TotalCost)
+	}
+}
@@ -0,0 +1,202 @@
+package fsp
+
+import "math"
+
+/*****************************************************************************/
+/* Lower bounds                                                              */
+/*****************************************************************************/
+
+// MAX_ASSIGNMENT bounds the trip length the O(n^3) assignment relaxation is
+// solved for, longer trips only get the cheap bounds
+const MAX_ASSIGNMENT int = 1000
+
+// bounds are the cheap lower bounds Greedy prunes with. Every day needs one
+// flight and every area has to be entered once, so what is left of a tour
+// costs at least the cheapest flights of the remaining days and at least
+// the cheapest flights into the areas not entered yet.
+type bounds struct {
+	// entry[a][d] is the cheapest flight into area a on day d that a tour
+	// can use, unreachable if there is none
+	entry   [][]Money
+	dayRest []Money // cheapest usable flights of day d and all later days
+	areaIn  []Money // cheapest usable flight into the area on any day
+	totalIn Money   // sum of areaIn
+}
+
+// usable reports whether a tour can take flight f at all: on day 1 it has
+// to leave the start city, later it can neither leave the home area nor stay
+// in its area, and only the last flight may enter the home area. A trip of
+// one day stays in the home area.
+func (p *Problem) usable(f *Flight) bool {
+	if f.FromArea == f.ToArea && p.length > 1 {
+		return false
+	}
+	if f.Day == 1 && f.From != p.start || f.Day > 1 && f.FromArea == p.goal {
+		return false
+	}
+	return (f.ToArea == p.goal) == (int(f.Day) == p.length)
+}
+
+func newBounds(p *Problem) *bounds {
+	areas := len(p.areaLookup.indexToName)
+	b := &bounds{entry: make([][]Money, areas),
+		dayRest: make([]Money, p.length+2),
+		areaIn:  make([]Money, areas),
+	}
+	for a := range b.entry {
+		b.entry[a] = make([]Money, p.length+1)
+		for d := range b.entry[a] {
+			b.entry[a][d] = unreachable
+		}
+	}
+	day := make([]Money, p.length+1)
+	for d := range day {
+		day[d] = unreachable
+	}
+	g := &p.indices.fromDayTo
+	for i := range g.flights {
+		f := &g.flights[i]
+		if !p.usable(f) {
+			continue
+		}
+		if f.Cost < b.entry[f.ToArea][f.Day] {
+			b.entry[f.ToArea][f.Day] = f.Cost
+		}
+		if f.Cost < day[f.Day] {
+			day[f.Day] = f.Cost
+		}
+	}
+	// missing flights make the problem infeasible, which the bounds do not
+	// need to know about
+	for d := p.length; d >= 1; d-- {
+		if day[d] == unreachable {
+			day[d] = 0
+		}
+		b.dayRest[d] = b.dayRest[d+1] + day[d]
+	}
+	for a := range b.entry {
+		b.areaIn[a] = unreachable
+		for _, c := range b.entry[a] {
+			if c < b.areaIn[a] {
+				b.areaIn[a] = c
+			}
+		}
+		if b.areaIn[a] == unreachable {
+			b.areaIn[a] = 0
+		}
+		b.totalIn += b.areaIn[a]
+	}
+	return b
+}
+
+// rest bounds the cost of the flights after day, entered is the sum of
+// areaIn of the areas entered so far
+func (b *bounds) rest(day Day, entered Money) Money {
+	r := b.dayRest[day+1]
+	if in := b.totalIn - entered; in > r {
+		r = in
+	}
+	return r
+}
+
+// LowerBound returns a lower bound on the cost of every tour of the problem.
+// Up to MAX_ASSIGNMENT areas it solves the relaxation that assigns every area
+// to a distinct day paying the cheapest usable flight into it on that day,
+// otherwise it is the larger of the day and area bounds. ok is false when the
+// relaxation proves that there is no tour at all.
+func LowerBound(p *Problem) (Money, bool) {
+	b := p.bounds()
+	cheap := b.rest(0, 0)
+	if p.length > MAX_ASSIGNMENT {
+		return cheap, true
+	}
+	// the home area is entered on the last day, the others on days 1..n-1
+	home := b.entry[p.goal][p.length]
+	if home == unreachable {
+		return 0, false
+	}
+	n := p.length - 1
+	cost := make([][]int64, 0, n)
+	for a := range b.entry {
+		if Area(a) == p.goal {
+			continue
+		}
+		row := make([]int64, n)
+		for d := range row {
+			row[d] = int64(b.entry[a][d+1])
+		}
+		cost = append(cost, row)
+	}
+	total, ok := assignment(cost, int64(unreachable))
+	if !ok {
+		return 0, false
+	}
+	if bound := Money(total) + home; bound > cheap {
+		return bound, true
+	}
+	return cheap, true
+}
+
+// assignment solves the square assignment problem by the Hungarian method
+// with potentials in O(n^3), entries of at least missing cannot be used. ok
+// is false when every assignment needs such an entry.
+func assignment(cost [][]int64, missing int64) (int64, bool) {
+	n := len(cost)
+	if n == 0 {
+		return 0, true
+	}
+	// rows and columns are 1-based, column 0 is the virtual start
+	u := make([]int64, n+1)
+	v := make([]int64, n+1)
+	match := make([]int, n+1) // row matched to each column
+	way := make([]int, n+1)
+	minv := make([]int64, n+1)
+	used := make([]bool, n+1)
+	for i := 1; i <= n; i++ {
+		match[0] = i
+		j0 := 0
+		for j := range minv {
+			minv[j], used[j] = math.MaxInt64, false
+		}
+		for match[j0] != 0 {
+			used[j0] = true
+			i0, delta, j1 := match[j0], int64(math.MaxInt64), -1
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				if c := cost[i0-1][j-1]; c < missing {
+					if cur := c - u[i0] - v[j]; cur < minv[j] {
+						minv[j], way[j] = cur, j0
+					}
+				}
+				if minv[j] < delta {
+					delta, j1 = minv[j], j
+				}
+			}
+			if j1 < 0 {
+				// no column left reachable from the alternating tree
+				return 0, false
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[match[j]] += delta
+					v[j] -= delta
+				} else if minv[j] != math.MaxInt64 {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			match[j0] = match[j1]
+			j0 = j1
+		}
+	}
+	total := int64(0)
+	for j := 1; j <= n; j++ {
+		total += cost[match[j]-1][j-1]
+	}
+	return total, true
+}
@@ -0,0 +1,48 @@
+package fsp
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestAssignment(t *testing.T) {
+	const x = 1000
+	tests := []struct {
+		cost  [][]int64
+		total int64
+		ok    bool
+	}{
+		{cost: nil, total: 0, ok: true},
+		{cost: [][]int64{{4, 1, 3}, {2, 0, 5}, {3, 2, 2}}, total: 5, ok: true},
+		{cost: [][]int64{{1, x}, {2, x}}, ok: false},
+		{cost: [][]int64{{x, 7}, {3, x}}, total: 10, ok: true},
+		{cost: [][]int64{{x, 2, 48, x}, {44, 23, 29, 29}, {9, 86, 86, 86}, {x, 19, 16, 19}}, total: 56, ok: true},
+	}
+	for ti, test := range tests {
+		total, ok := assignment(test.cost, x)
+		if ok != test.ok || ok && total != test.total {
+			t.Fatal(ti, "assignment", total, ok)
+		}
+	}
+}
+
+func TestLowerBound(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for ti := 0; ti < 200; ti++ {
+		input := randomInput(rng, 2+rng.Intn(5))
+		problem, err := ReadInput(bytes.NewReader([]byte(input)), false)
+		if err != nil {
+			t.Fatal(ti, err)
+		}
+		exact, feasible := NewExact(problem).Optimum(context.Background())
+		lb, ok := LowerBound(problem)
+		if feasible && !ok {
+			t.Fatal(ti, "bound claims a feasible problem infeasible\n", input)
+		}
+		if feasible && lb > exact.TotalCost {
+			t.Fatal(ti, "bound", lb, "above the optimum", exact.TotalCost, "\n", input)
+		}
+	}
+}
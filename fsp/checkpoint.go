@@ -0,0 +1,102 @@
+package fsp
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/*****************************************************************************/
+/* Checkpoints                                                               */
+/*****************************************************************************/
+
+// checkpoint writes the best tour of a comm to a file in the PrintSolution
+// format, ReadSolution loads it back
+type checkpoint struct {
+	path  string
+	every time.Duration
+	// last and pending are guarded by the comm mutex
+	last    time.Time // of the last write
+	pending bool      // a write is scheduled
+	// written is the cost of the tour in the file, guarded by mutex which
+	// also keeps writes apart
+	mutex   sync.Mutex
+	written Money
+}
+
+// Checkpoint makes the comm write its best tour to path whenever it improves,
+// at most once every interval. Call it before solving starts and Flush
+// before exiting, an improvement waiting for its slot is lost otherwise.
+func (c *SolutionComm) Checkpoint(path string, every time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.checkpoint = &checkpoint{path: path, every: every, written: unreachable}
+}
+
+// improved schedules a checkpoint write, c.mutex has to be held
+func (c *SolutionComm) improved() {
+	ck := c.checkpoint
+	if ck == nil || ck.pending {
+		return
+	}
+	ck.pending = true
+	time.AfterFunc(ck.every-time.Since(ck.last), func() {
+		if err := c.Flush(); err != nil {
+			logln(Normal, "checkpoint failed:", err)
+		}
+	})
+}
+
+// Flush writes the best tour to the checkpoint file unless the file already
+// holds a tour as good.
+func (c *SolutionComm) Flush() error {
+	c.mutex.Lock()
+	ck := c.checkpoint
+	if ck == nil {
+		c.mutex.Unlock()
+		return nil
+	}
+	ck.pending, ck.last = false, time.Now()
+	s := Solution{make([]*Flight, len(c.best.Flights)), c.best.TotalCost}
+	copy(s.Flights, c.best.Flights)
+	c.mutex.Unlock()
+
+	ck.mutex.Lock()
+	defer ck.mutex.Unlock()
+	if len(s.Flights) == 0 || s.TotalCost >= ck.written {
+		return nil
+	}
+	if err := writeAtomically(ck.path, c.problem, s); err != nil {
+		return err
+	}
+	ck.written = s.TotalCost
+	return nil
+}
+
+// writeAtomically writes the tour to a temporary file next to path and
+// renames it over path, a crash leaves either the old or the new tour
+func writeAtomically(path string, p *Problem, s Solution) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	w := bufio.NewWriter(f)
+	PrintSolution(w, p, s)
+	if err = w.Flush(); err == nil {
+		// CreateTemp keeps the file private
+		err = f.Chmod(0644)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
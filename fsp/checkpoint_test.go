@@ -0,0 +1,56 @@
+package fsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckpoint(t *testing.T) {
+	problem, _ := solvedSample(t)
+	tour := func(text string) Solution {
+		s, err := ReadSolution(strings.NewReader(text), problem)
+		if err != nil || len(Validate(problem, s)) > 0 {
+			t.Fatal("bad tour", err, text)
+		}
+		return s
+	}
+	stored := func(path string) Money {
+		f, err := os.Open(path)
+		if err != nil {
+			return 0
+		}
+		defer f.Close()
+		s, err := ReadSolution(f, problem)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.TotalCost
+	}
+	path := filepath.Join(t.TempDir(), "best.txt")
+	c := NewComm(problem)
+	c.Checkpoint(path, time.Hour)
+	// the first tour is written right away, in the background
+	c.Send(tour("130\nASD GDO 1 10\nGDO SKT 2 90\nSKT ASD 3 30\n"))
+	for start := time.Now(); stored(path) != 130; time.Sleep(time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("no checkpoint written")
+		}
+	}
+	// the next has to wait for the hour to pass or Flush
+	c.Send(tour("100\nASD MXT 1 50\nMXT SKT 2 20\nSKT ASD 3 30\n"))
+	if cost := stored(path); cost != 130 {
+		t.Fatal("rate limit ignored", cost)
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if cost := stored(path); cost != 100 {
+		t.Fatal("flush did not write", cost)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Fatal("temporary files left", entries)
+	}
+}
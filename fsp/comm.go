@@ -0,0 +1,232 @@
+package fsp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Comm is how solvers publish their tours and learn about the global best.
+type Comm interface {
+	Send(r Solution) Money
+	Current() Solution
+}
+
+// Solver searches for tours and publishes them through comm until it has
+// nothing left to search or ctx is done.
+type Solver interface {
+	Solve(ctx context.Context, comm Comm)
+}
+
+// Outcome is how a run ended.
+type Outcome int
+
+const (
+	Found      Outcome = iota // some solver found a tour
+	NotFound                  // no tour was found, nor proven not to exist
+	Infeasible                // a solver proved that there is no tour
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Found:
+		return "found"
+	case NotFound:
+		return "not found"
+	case Infeasible:
+		return "infeasible"
+	}
+	return fmt.Sprintf("Outcome(%d)", int(o))
+}
+
+// Meta describes how the best solution was found.
+type Meta struct {
+	Solver       string        // solver that sent the best tour
+	Elapsed      time.Duration // since the comm was created
+	Improvements int           // number of strictly better tours received
+	Seed         int64         // master seed of the run, set by the caller
+	LowerBound   Money         // on the cost of any tour, 0 when unknown, set by the caller
+	Outcome      Outcome
+	Rejected     int // number of invalid tours received
+}
+
+type SolutionComm struct {
+	problem    *Problem
+	mutex      *sync.Mutex
+	best       Solution
+	meta       Meta
+	start      time.Time
+	exited     chan bool
+	infeasible bool
+	// cancel stops the solver started by Run
+	cancel     context.CancelFunc
+	checkpoint *checkpoint
+}
+
+func NewComm(problem *Problem) *SolutionComm {
+	initBest := Solution{}
+	initBest.TotalCost = unreachable
+	return &SolutionComm{
+		problem: problem,
+		mutex:   &sync.Mutex{},
+		best:    initBest,
+		start:   time.Now(),
+		exited:  make(chan bool, 1),
+	}
+}
+
+// Run starts the solver in its own goroutine, attributing its tours to name,
+// and marks the comm Done when it returns. A panic of the solver is
+// recovered, see supervise.
+func (c *SolutionComm) Run(ctx context.Context, name string, s Solver) {
+	c.mutex.Lock()
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.mutex.Unlock()
+	go func() {
+		defer c.Done()
+		supervise(ctx, WithSolver(c, name), name, s)
+	}()
+}
+
+// Infeasible records the proof that the problem has no tour and stops the
+// solver started by Run, there is nothing left to search for.
+func (c *SolutionComm) Infeasible() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.best.Flights) > 0 {
+		// a tour disproves it, trust the validated tour
+		logln(Normal, "infeasibility claimed for a problem with a tour")
+		return
+	}
+	c.infeasible = true
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+func (c *SolutionComm) Current() Solution {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	flights := make([]*Flight, len(c.best.Flights))
+	copy(flights, c.best.Flights)
+	return Solution{flights, c.best.TotalCost}
+}
+func (c *SolutionComm) Send(r Solution) Money {
+	return c.sendAs(r, "")
+}
+func (c *SolutionComm) sendAs(r Solution, solver string) Money {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if vs := Validate(c.problem, r); len(vs) > 0 {
+		// a broken solver must not cost the tours of the others
+		c.meta.Rejected++
+		logln(Normal, "rejected invalid solution from", solverName(solver), vs[0])
+		return c.best.TotalCost
+	}
+	// the cost of the empty best is only a placeholder, a tie keeps the
+	// tour and its attribution
+	bestCost, have := c.best.TotalCost, len(c.best.Flights) > 0
+	if have && bestCost <= r.TotalCost {
+		return bestCost
+	}
+
+	c.meta.Improvements++
+	c.meta.Solver, c.meta.Elapsed = solver, time.Since(c.start)
+
+	flights := make([]*Flight, len(r.Flights))
+	copy(flights, r.Flights)
+	sort.Sort(byDay(flights))
+	c.best = Solution{flights, r.TotalCost}
+	c.improved()
+	return r.TotalCost
+}
+
+// Meta describes how the current best solution was found.
+func (c *SolutionComm) Meta() Meta {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	meta := c.meta
+	switch {
+	case len(c.best.Flights) > 0:
+		meta.Outcome = Found
+	case c.infeasible:
+		meta.Outcome = Infeasible
+	default:
+		meta.Outcome = NotFound
+	}
+	return meta
+}
+
+type infeasibleReporter interface {
+	Infeasible()
+}
+
+// proveInfeasible tells comm that the problem has no tour when it keeps
+// track of that
+func proveInfeasible(comm Comm) {
+	if r, ok := comm.(infeasibleReporter); ok {
+		r.Infeasible()
+	}
+}
+
+func solverName(solver string) string {
+	if solver == "" {
+		return "unnamed solver"
+	}
+	return solver
+}
+
+type solverSender interface {
+	sendAs(r Solution, solver string) Money
+}
+
+// named attributes the tours sent through it to a solver, when nested the
+// name given closest to the solver wins
+type named struct {
+	Comm
+	solver string
+}
+
+// WithSolver wraps comm so that tours sent through it are attributed to
+// solver in Meta.
+func WithSolver(comm Comm, solver string) Comm {
+	return &named{comm, solver}
+}
+
+func (n *named) Send(r Solution) Money {
+	return n.sendAs(r, n.solver)
+}
+func (n *named) sendAs(r Solution, solver string) Money {
+	if s, ok := n.Comm.(solverSender); ok {
+		return s.sendAs(r, solver)
+	}
+	return n.Comm.Send(r)
+}
+
+func (n *named) Infeasible() {
+	proveInfeasible(n.Comm)
+}
+
+// Done records that the solver has exited.
+func (c *SolutionComm) Done() {
+	c.exited <- true
+}
+
+// Wait blocks until the solver is Done. Once ctx is done the solver has
+// grace to notice and exit, Wait returns false if it did not.
+func (c *SolutionComm) Wait(ctx context.Context, grace time.Duration) bool {
+	select {
+	case <-c.exited:
+		return true
+	case <-ctx.Done():
+	}
+	hard := time.NewTimer(grace)
+	defer hard.Stop()
+	select {
+	case <-c.exited:
+		return true
+	case <-hard.C:
+		return false
+	}
+}
@@ -0,0 +1,123 @@
+package fsp
+
+import "math"
+
+/*****************************************************************************/
+/* Delta cache                                                               */
+/*****************************************************************************/
+
+// infeasible marks a move without the flights it needs
+const infeasible = math.MaxInt64
+
+// deltaCache keeps the cost change of every move bestFlightSwap and
+// bestAreaSwap consider on a tour. The change of swapFlights(i, j) only
+// depends on flights i-1, i, j-1 and j, that of swapInArea(fi, x) on flights
+// fi-1 and fi, so after the tour changes update evaluates again just the
+// moves touching a changed flight.
+type deltaCache struct {
+	s      *Solution
+	g      Graph
+	areadb AreaDb
+	n      int
+	seen   []*Flight // the tour the deltas belong to
+	swap   []int64   // delta of swapFlights(i, j) at i*n+j, 1 <= i < j <= n-2
+	cities [][]City  // candidates of swapInArea(fi, x) for 1 <= fi <= n-1
+	area   [][]int64 // delta of swapInArea(fi, cities[fi][k])
+	stale  []bool    // per position, scratch of update
+}
+
+func newDeltaCache(s *Solution, g Graph, areadb AreaDb) *deltaCache {
+	n := len(s.Flights)
+	c := &deltaCache{s: s, g: g, areadb: areadb, n: n,
+		seen:   make([]*Flight, n),
+		swap:   make([]int64, n*n),
+		cities: make([][]City, n),
+		area:   make([][]int64, n),
+		stale:  make([]bool, n+1),
+	}
+	for i := range c.stale {
+		c.stale[i] = true
+	}
+	c.refresh()
+	return c
+}
+
+func (c *deltaCache) delta(ok bool, newCost Money) int64 {
+	if !ok {
+		return infeasible
+	}
+	return int64(newCost) - int64(c.s.TotalCost)
+}
+
+// update brings the deltas up to date with the current tour
+func (c *deltaCache) update() {
+	changed := false
+	for p, f := range c.s.Flights {
+		if f != c.seen[p] {
+			// moves at positions p and p+1 use flight p
+			c.stale[p], c.stale[p+1], changed = true, true, true
+		}
+	}
+	if changed {
+		c.refresh()
+	}
+}
+
+// refresh evaluates the moves at stale positions again
+func (c *deltaCache) refresh() {
+	s, n := *c.s, c.n
+	for i := 1; i < n-2; i++ {
+		for j := i + 1; j <= n-2; j++ {
+			if c.stale[i] || c.stale[j] {
+				c.swap[i*n+j] = c.delta(swapFlights(s, c.g, i, j, false))
+			}
+		}
+	}
+	for fi := 1; fi <= n-1; fi++ {
+		if !c.stale[fi] {
+			continue
+		}
+		area := c.areadb.areaToCities[c.areadb.cityToArea[s.Flights[fi].From]]
+		c.cities[fi] = area
+		if cap(c.area[fi]) < len(area) {
+			c.area[fi] = make([]int64, len(area))
+		}
+		c.area[fi] = c.area[fi][:len(area)]
+		for k, x := range area {
+			c.area[fi][k] = c.delta(swapInArea(s, c.g, fi, x, false))
+		}
+	}
+	copy(c.seen, s.Flights)
+	for i := range c.stale {
+		c.stale[i] = false
+	}
+}
+
+// bestFlightSwap is bestFlightSwap(s, g, n-2) of the current tour
+func (c *deltaCache) bestFlightSwap() (int, int) {
+	c.update()
+	bi, bj, best := -1, -1, int64(infeasible)
+	for i := 1; i < c.n-2; i++ {
+		row := c.swap[i*c.n : (i+1)*c.n]
+		for j := i + 1; j <= c.n-2; j++ {
+			if row[j] < best {
+				best, bi, bj = row[j], i, j
+			}
+		}
+	}
+	return bi, bj
+}
+
+// bestAreaSwap is bestAreaSwap(s, g, n-1, ...) of the current tour
+func (c *deltaCache) bestAreaSwap() (int, City) {
+	c.update()
+	bfi, bci, best := -1, City(0), int64(infeasible)
+	for fi := 1; fi <= c.n-1; fi++ {
+		for k, d := range c.area[fi] {
+			if d < best {
+				best, bfi, bci = d, fi, c.cities[fi][k]
+			}
+		}
+	}
+	return bfi, bci
+}
@@ -0,0 +1,89 @@
+package fsp
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func TestDeltaCache(t *testing.T) {
+	in, err := os.Open("../data/2.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	problem, err := ReadInput(in, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &testcomm{}
+	NewGreedy(problem).Solve(context.Background(), c)
+	s := c.solution
+	g, areadb, n := problem.indices.fromDayTo, problem.areaDb, len(s.Flights)
+	deltas := newDeltaCache(&s, g, areadb)
+	rng := rand.New(rand.NewSource(1))
+	for k := 0; k < 300; k++ {
+		i, j := bestFlightSwap(s, g, n-2)
+		if ci, cj := deltas.bestFlightSwap(); ci != i || cj != j {
+			t.Fatal(k, "flight swap", ci, cj, "!=", i, j)
+		}
+		fi, x := bestAreaSwap(s, g, n-1, s.Flights, areadb)
+		if cfi, cx := deltas.bestAreaSwap(); cfi != fi || cx != x {
+			t.Fatal(k, "area swap", cfi, cx, "!=", fi, x)
+		}
+		// walk on with whatever random move is feasible
+		switch k % 3 {
+		case 0:
+			i, j := randomFlightSwap(rng, n-2)
+			if ok, newCost := swapFlights(s, g, i, j, true); ok {
+				s.TotalCost = newCost
+			}
+		case 1:
+			fi, x := randomAreaSwap(rng, n-1, s.Flights, areadb)
+			if ok, newCost := swapInArea(s, g, fi, x, true); ok {
+				s.TotalCost = newCost
+			}
+		case 2:
+			m := randomSegmentMove(rng, n, segmentLength)
+			if ok, newCost := m.try(s, g, true); ok {
+				s.TotalCost = newCost
+			}
+		}
+	}
+}
+
+func BenchmarkBestSwap(b *testing.B) {
+	in, err := os.Open("../data/2.in")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer in.Close()
+	problem, err := ReadInput(in, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	c := &testcomm{}
+	NewGreedy(problem).Solve(context.Background(), c)
+	s := c.solution
+	g, areadb, n := problem.indices.fromDayTo, problem.areaDb, len(s.Flights)
+	rng := rand.New(rand.NewSource(1))
+	deltas := newDeltaCache(&s, g, areadb)
+	b.Run("scan", func(b *testing.B) {
+		for k := 0; k < b.N; k++ {
+			bestFlightSwap(s, g, n-2)
+			bestAreaSwap(s, g, n-1, s.Flights, areadb)
+		}
+	})
+	b.Run("cache", func(b *testing.B) {
+		for k := 0; k < b.N; k++ {
+			// one random feasible change per search, as in a descent
+			fi, x := randomAreaSwap(rng, n-1, s.Flights, areadb)
+			if ok, newCost := swapInArea(s, g, fi, x, true); ok {
+				s.TotalCost = newCost
+			}
+			deltas.bestFlightSwap()
+			deltas.bestAreaSwap()
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package fsp
+
+import "fmt"
+
+// CauseKind classifies why a problem has no tour.
+type CauseKind int
+
+const (
+	NoDeparture  CauseKind = iota // start city has no flight on day 1
+	EmptyDay                      // no flight a tour can take on the day
+	NoReturn                      // no flight into the home area on the last day
+	NoInbound                     // area cannot be entered on any day
+	NoOutbound                    // area cannot be left on any day
+	NoAssignment                  // areas cannot be entered on distinct days
+)
+
+var causeNames = []string{"no departure", "empty day", "no return",
+	"no inbound", "no outbound", "no assignment"}
+
+func (k CauseKind) String() string {
+	if int(k) < len(causeNames) {
+		return causeNames[k]
+	}
+	return fmt.Sprintf("cause %d", int(k))
+}
+
+// Cause is one reason why a problem has no tour.
+type Cause struct {
+	Kind CauseKind
+	Msg  string
+}
+
+func (c Cause) String() string {
+	return fmt.Sprintf("%v: %v", c.Kind, c.Msg)
+}
+
+// census records which days have flights and which areas a tour can enter
+// and leave, ReadInput takes it before pruning so that Diagnose describes the
+// flights of the input
+type census struct {
+	onDay   []bool // any flight on the day
+	in, out []bool // a usable flight into or out of the area
+	home    bool   // a usable flight into the home area
+}
+
+func newCensus(p *Problem) *census {
+	areas := len(p.areaLookup.indexToName)
+	c := &census{onDay: make([]bool, p.length+1),
+		in:  make([]bool, areas),
+		out: make([]bool, areas),
+	}
+	g := &p.indices.fromDayTo
+	for i := range g.flights {
+		f := &g.flights[i]
+		if int(f.Day) > p.length {
+			continue
+		}
+		c.onDay[f.Day] = true
+		if !p.usable(f) {
+			continue
+		}
+		c.in[f.ToArea], c.out[f.FromArea] = true, true
+		if f.ToArea == p.goal {
+			c.home = true
+		}
+	}
+	return c
+}
+
+// Diagnose looks for the simple reasons why the problem has no tour in the
+// flights of the input: days without flights and, among the flights a tour
+// can take at all (see usable), none leaving the start city, entering the
+// home area on the last day, or entering or leaving another area. Those can
+// be entered on days 1 to n-1 and left on days 2 to n. When every area and
+// day has flights it tries the assignment relaxation of LowerBound. None
+// means that the flights are there but every way through them revisits an
+// area.
+func Diagnose(p *Problem) []Cause {
+	var cs []Cause
+	add := func(kind CauseKind, format string, args ...interface{}) {
+		cs = append(cs, Cause{kind, fmt.Sprintf(format, args...)})
+	}
+	c := p.census
+	if c == nil {
+		c = newCensus(p)
+	}
+	areas := len(p.areaLookup.indexToName)
+	onDay, in, out := c.onDay, c.in, c.out
+	if !onDay[1] {
+		add(NoDeparture, "%v has no flight on day 1", p.CityName(p.start))
+	}
+	for d := 2; d <= p.length; d++ {
+		if !onDay[d] {
+			add(EmptyDay, "no flight on day %d", d)
+		}
+	}
+	if !c.home && onDay[p.length] {
+		add(NoReturn, "no flight into home area %v on day %d", p.AreaName(p.goal), p.length)
+	}
+	for a := 0; a < areas; a++ {
+		if Area(a) == p.goal {
+			continue
+		}
+		if !in[a] {
+			add(NoInbound, "no flight into area %v on days 1 to %d", p.AreaName(Area(a)), p.length-1)
+		}
+		if !out[a] {
+			add(NoOutbound, "no flight out of area %v on days 2 to %d", p.AreaName(Area(a)), p.length)
+		}
+	}
+	if len(cs) == 0 {
+		if _, ok := LowerBound(p); !ok {
+			add(NoAssignment, "the %d areas other than home cannot be entered on distinct days", areas-1)
+		}
+	}
+	return cs
+}
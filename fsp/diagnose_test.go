@@ -0,0 +1,83 @@
+package fsp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	const fiveAreas = `5 HHH
+H
+HHH
+A
+AAA
+B
+BBB
+C
+CCC
+D
+DDD
+`
+	tests := []struct {
+		input string
+		kinds []CauseKind
+	}{
+		{input: sampleHeader + `ASD MXT 1 50
+MXT SKT 2 20
+SKT ASD 3 30
+`},
+		{input: sampleHeader + `ASD SKT 1 10
+SKT MXT 2 10
+MXT SKT 3 10
+`, kinds: []CauseKind{NoReturn, NoOutbound}},
+		{input: sampleHeader + `SKT MXT 2 10
+MXT ASD 3 10
+`, kinds: []CauseKind{NoDeparture, NoInbound}},
+		{input: sampleHeader + `ASD MXT 1 10
+GDO SKT 3 10
+SKT ASD 3 10
+`, kinds: []CauseKind{EmptyDay, NoInbound, NoOutbound}},
+		// A and B can only be entered on day 1
+		{input: fiveAreas + `HHH AAA 1 10
+HHH BBB 1 10
+AAA CCC 2 10
+BBB DDD 2 10
+CCC DDD 3 10
+DDD CCC 3 10
+CCC DDD 4 10
+DDD CCC 4 10
+CCC HHH 5 10
+DDD HHH 5 10
+`, kinds: []CauseKind{NoAssignment}},
+		// pruning drops the flight into D, the cause is the missing way out
+		{input: `4 HHH
+H
+HHH
+B
+BBB
+C
+CCC
+D
+DDD
+HHH BBB 1 10
+BBB CCC 2 10
+CCC BBB 3 10
+BBB HHH 4 10
+HHH DDD 1 1
+`, kinds: []CauseKind{NoOutbound}},
+	}
+	for ti, test := range tests {
+		p, err := ReadInput(strings.NewReader(test.input), false)
+		if err != nil {
+			t.Fatal(ti, err)
+		}
+		var kinds []CauseKind
+		for _, c := range Diagnose(p) {
+			kinds = append(kinds, c.Kind)
+		}
+		if !reflect.DeepEqual(kinds, test.kinds) {
+			t.Fatal(ti, "causes", Diagnose(p))
+		}
+	}
+}
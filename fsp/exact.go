@@ -0,0 +1,276 @@
+package fsp
+
+import (
+	"context"
+	"math"
+	"math/bits"
+)
+
+/*****************************************************************************/
+/* Exact                                                                     */
+/*****************************************************************************/
+
+// MAX_EXACT_STATES bounds the DP table, which has an entry for every city
+// outside the home area in half of the 2^(areas-1) visited masks
+const MAX_EXACT_STATES int = 1 << 24
+
+// MAX_EXACT_MOVES bounds the flights relaxed while filling the table, so that
+// the DP ends well within the time limit of 20 areas
+const MAX_EXACT_MOVES int = 1 << 28
+
+const unreachable = Money(math.MaxUint32)
+
+// Exact is a Held-Karp style dynamic program over (visited areas, city), the
+// day is implied by the number of visited areas and the city is one of the
+// visited areas. It returns the proven optimum but only fits small
+// instances, see CanSolveExactly.
+type Exact struct {
+	problem *Problem
+	bit     []uint   // area -> bit in the visited mask, home area has none
+	cityBit []uint   // city -> bit of its area
+	local   []int    // city -> index among the cities of its area
+	cities  [][]City // bit -> cities of the area
+	// the row of mask starts at base[mask] and holds the cities of the
+	// areas in mask ordered by bit, width[mask] of them
+	base  []int
+	width []int
+	// hops[day*cities+c] are the flights from c on day that do not go home
+	hops    [][]hop
+	table   []Money // cheapest cost of (mask, city), unreachable if none
+	scratch int     // table entries from here on soak up revisits
+}
+
+// hop is a flight of the DP, it adds area bit to the visited mask and lands
+// on city local of the area
+type hop struct {
+	bit   uint32
+	local int32
+	cost  Money
+}
+
+func NewExact(problem *Problem) *Exact {
+	return &Exact{problem: problem}
+}
+
+// exactStates is the size of the DP table of p
+func exactStates(p *Problem) int {
+	if p.length == 1 {
+		return 0
+	}
+	away := len(p.cityLookup.indexToName) - len(p.areaDb.areaToCities[p.goal])
+	return away << uint(p.length-2)
+}
+
+// exactMoves is how many flights the DP relaxes at most: a flight on day d
+// leaves every row of the d-1 visited areas that hold its city
+func exactMoves(p *Problem) int {
+	k := p.length - 1
+	// rows[d] is the number of masks of d-1 areas containing a given area
+	rows := make([]int, p.length+1)
+	rows[1] = 1
+	for d, c := 2, 1; d <= p.length; d++ {
+		rows[d] = c
+		c = c * (k - d + 1) / (d - 1)
+	}
+	moves := 0
+	for i := range p.flights {
+		f := &p.flights[i]
+		switch {
+		case f.Day == 1:
+			if f.From == p.start {
+				moves++
+			}
+		case int(f.Day) <= p.length && f.FromArea != p.goal:
+			moves += rows[f.Day]
+		}
+	}
+	return moves
+}
+
+// CanSolveExactly reports whether the DP table for the problem fits into
+// MAX_EXACT_STATES and filling it takes at most MAX_EXACT_MOVES.
+func CanSolveExactly(p *Problem) bool {
+	if p.length-1 >= 31 {
+		return false
+	}
+	return exactStates(p) <= MAX_EXACT_STATES && exactMoves(p) <= MAX_EXACT_MOVES
+}
+
+func (e *Exact) state(mask uint, c City) int {
+	below := uint(1)<<e.cityBit[c] - 1
+	return e.base[mask] + e.width[mask&below] + e.local[c]
+}
+
+// row appends the cities of the areas in mask to buf in table order
+func (e *Exact) row(mask uint, buf []City) []City {
+	for ; mask != 0; mask &= mask - 1 {
+		buf = append(buf, e.cities[bits.TrailingZeros(mask)]...)
+	}
+	return buf
+}
+
+// into fills into[b] with the index of the first city of area b in the row
+// of mask plus b, areas already in mask go to the scratch entries
+func (e *Exact) into(mask uint, into []int) {
+	for b := range into {
+		into[b] = e.scratch
+		if m := uint(1) << uint(b); mask&m == 0 {
+			into[b] = e.base[mask|m] + e.width[mask&(m-1)]
+		}
+	}
+}
+
+// layout numbers the areas and cities, sizes the rows of the table and packs
+// the flights into hops
+func (e *Exact) layout() {
+	p := e.problem
+	n := p.length
+	cities := len(p.cityLookup.indexToName)
+	e.bit = make([]uint, n)
+	e.cities = make([][]City, 0, n-1)
+	e.cityBit = make([]uint, cities)
+	e.local = make([]int, cities)
+	widest := 0
+	for a := 0; a < n; a++ {
+		if Area(a) == p.goal {
+			continue
+		}
+		b := uint(len(e.cities))
+		e.bit[a] = b
+		area := p.areaDb.areaToCities[Area(a)]
+		for i, c := range area {
+			e.cityBit[c], e.local[c] = b, i
+		}
+		e.cities = append(e.cities, area)
+		if len(area) > widest {
+			widest = len(area)
+		}
+	}
+	full := uint(1)<<uint(n-1) - 1
+	e.width = make([]int, full+1)
+	e.base = make([]int, full+2)
+	for mask := uint(1); mask <= full; mask++ {
+		e.width[mask] = e.width[mask&(mask-1)] + len(e.cities[bits.TrailingZeros(mask)])
+	}
+	for mask := uint(0); mask <= full; mask++ {
+		e.base[mask+1] = e.base[mask] + e.width[mask]
+	}
+	e.scratch = e.base[full+1]
+	e.table = make([]Money, e.scratch+widest)
+
+	g := p.indices.fromDayTo
+	e.hops = make([][]hop, n*cities)
+	for day := 1; day < n; day++ {
+		for c := 0; c < cities; c++ {
+			dst := g.departures(City(c), Day(day))
+			hops := make([]hop, 0, len(dst))
+			for _, f := range dst {
+				if f.ToArea != p.goal {
+					hops = append(hops, hop{uint32(e.bit[f.ToArea]), int32(e.local[f.To]), f.Cost})
+				}
+			}
+			e.hops[day*cities+c] = hops
+		}
+	}
+}
+
+// Optimum computes the cheapest tour, ok is false when no tour exists or ctx
+// was done before the table was complete.
+func (e *Exact) Optimum(ctx context.Context) (Solution, bool) {
+	p := e.problem
+	e.layout()
+	for i := range e.table {
+		e.table[i] = unreachable
+	}
+	full := uint(1)<<uint(p.length-1) - 1
+	cities := len(p.cityLookup.indexToName)
+	into := make([]int, len(e.cities))
+	relax := func(cost Money, hops []hop) {
+		for _, h := range hops {
+			s := into[h.bit] + int(h.local)
+			if v := cost + h.cost; v < e.table[s] {
+				e.table[s] = v
+			}
+		}
+	}
+	best, bestLast := unreachable, (*Flight)(nil)
+	finish := func(cost Money, c City) {
+		dst := p.indices.fromDayTo.departures(c, Day(p.length))
+		for i := range dst {
+			if f := &dst[i]; f.ToArea == p.goal && cost+f.Cost < best {
+				best, bestLast = cost+f.Cost, f
+			}
+		}
+	}
+	if full == 0 {
+		finish(0, p.start)
+	} else {
+		e.into(0, into)
+		relax(0, e.hops[cities+int(p.start)])
+	}
+	var row []City
+	for mask := uint(1); mask <= full; mask++ {
+		if mask&1023 == 0 && ctx.Err() != nil {
+			return Solution{}, false
+		}
+		day := bits.OnesCount(mask) + 1
+		row = e.row(mask, row[:0])
+		costs := e.table[e.base[mask]:e.base[mask+1]]
+		e.into(mask, into)
+		for i, c := range row {
+			switch {
+			case costs[i] == unreachable:
+			case mask == full:
+				finish(costs[i], c)
+			default:
+				relax(costs[i], e.hops[day*cities+int(c)])
+			}
+		}
+	}
+	if bestLast == nil {
+		return Solution{}, false
+	}
+	return NewSolution(e.path(full, bestLast)), true
+}
+
+// path walks the table back from the last flight of the optimal tour
+func (e *Exact) path(mask uint, last *Flight) []*Flight {
+	p := e.problem
+	g := p.indices.fromDayTo
+	flights := make([]*Flight, p.length)
+	flights[p.length-1] = last
+	c := last.From
+	for day := p.length - 1; day >= 1; day-- {
+		want := e.table[e.state(mask, c)]
+		mask &^= uint(1) << e.cityBit[c]
+		// the tour starts from the start city at no cost
+		prev, costs := []City{p.start}, []Money{0}
+		if mask != 0 {
+			prev, costs = e.row(mask, nil), e.table[e.base[mask]:e.base[mask+1]]
+		}
+		found := false
+		for i := 0; i < len(prev) && !found; i++ {
+			if costs[i] == unreachable {
+				continue
+			}
+			f := g.get(prev[i], Day(day), c)
+			if f != nil && costs[i]+f.Cost == want {
+				flights[day-1] = f
+				c, found = prev[i], true
+			}
+		}
+		if !found {
+			panic("exact: broken dp table")
+		}
+	}
+	return flights
+}
+
+func (e *Exact) Solve(ctx context.Context, comm Comm) {
+	if s, ok := e.Optimum(ctx); ok {
+		comm.Send(s)
+	} else if ctx.Err() == nil {
+		// the table is complete and holds no tour
+		proveInfeasible(comm)
+	}
+}
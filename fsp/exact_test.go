@@ -0,0 +1,143 @@
+package fsp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+// randomInput generates an instance with at most 10 cities, small enough
+// for Greedy to search exhaustively
+func randomInput(rng *rand.Rand, areas int) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d C00\n", areas)
+	cities := []string{}
+	for a := 0; a < areas; a++ {
+		fmt.Fprintf(&b, "A%d\n", a)
+		n := 1
+		if len(cities)+areas-a < 10 && rng.Intn(2) == 0 {
+			n = 2
+		}
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			c := fmt.Sprintf("C%d%d", a, i)
+			cities = append(cities, c)
+			b.WriteString(c)
+		}
+		b.WriteString("\n")
+	}
+	for i := 0; i < areas*areas*3; i++ {
+		from, to := cities[rng.Intn(len(cities))], cities[rng.Intn(len(cities))]
+		if from[1] == to[1] {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s %d %d\n", from, to, rng.Intn(areas+1), rng.Intn(100)+1)
+	}
+	return b.String()
+}
+
+func TestExactOracle(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	feasible := 0
+	for ti := 0; ti < 200; ti++ {
+		input := randomInput(rng, 2+rng.Intn(5))
+		problem, err := ReadInput(bytes.NewReader([]byte(input)), false)
+		if err != nil {
+			t.Fatal(ti, err)
+		}
+		c := &testcomm{}
+		NewGreedy(problem).Solve(context.Background(), c)
+		exact, ok := NewExact(problem).Optimum(context.Background())
+		if ok != (c.solution.Flights != nil) {
+			t.Fatal(ti, "feasibility mismatch, exact", ok, "\n", input)
+		}
+		if !ok {
+			continue
+		}
+		feasible++
+		if len(Validate(problem, exact)) > 0 {
+			t.Fatal(ti, "invalid exact tour\n", input)
+		}
+		if len(Validate(problem, c.solution)) > 0 {
+			t.Fatal(ti, "invalid greedy tour\n", input)
+		}
+		if exact.TotalCost != c.solution.TotalCost {
+			t.Fatal(ti, "exact", exact.TotalCost, "!= greedy", c.solution.TotalCost, "\n", input)
+		}
+	}
+	if feasible == 0 {
+		t.Fatal("no feasible instance generated")
+	}
+}
+
+// TestExact20 plants a tour of cost 1 flights among expensive ones in an
+// instance of 20 areas
+func TestExact20(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const areas = 20
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d C00\n", areas)
+	for a := 0; a < areas; a++ {
+		fmt.Fprintf(&b, "Area%d\nC%02d D%02d\n", a, a, a)
+	}
+	order := rng.Perm(areas - 1)
+	prev := "C00"
+	for d, a := range order {
+		next := fmt.Sprintf("C%02d", a+1)
+		fmt.Fprintf(&b, "%s %s %d 1\n", prev, next, d+1)
+		prev = next
+	}
+	fmt.Fprintf(&b, "%s C00 %d 1\n", prev, areas)
+	for i := 0; i < 10*areas*areas; i++ {
+		from := fmt.Sprintf("%c%02d", "CD"[rng.Intn(2)], rng.Intn(areas))
+		to := fmt.Sprintf("%c%02d", "CD"[rng.Intn(2)], rng.Intn(areas))
+		fmt.Fprintf(&b, "%s %s %d %d\n", from, to, rng.Intn(areas)+1, 10+rng.Intn(100))
+	}
+	problem, err := ReadInput(&b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CanSolveExactly(problem) {
+		t.Fatal("20 areas too large for exact,", exactStates(problem), "states", exactMoves(problem), "moves")
+	}
+	s, ok := NewExact(problem).Optimum(context.Background())
+	if !ok || s.TotalCost != areas {
+		t.Fatal("optimum", s.TotalCost, "!=", areas)
+	}
+	if vs := Validate(problem, s); len(vs) > 0 {
+		t.Fatal("invalid exact tour", vs)
+	}
+}
+
+func TestExactData(t *testing.T) {
+	tests := []struct {
+		file string
+		cost Money
+	}{
+		{file: "../data/0.in", cost: 100},
+		{file: "../data/1.in", cost: 1396},
+	}
+	for _, test := range tests {
+		in, err := os.Open(test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		problem, err := ReadInput(in, false)
+		in.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !CanSolveExactly(problem) {
+			t.Fatal(test.file, "too large for exact")
+		}
+		s, ok := NewExact(problem).Optimum(context.Background())
+		if !ok || s.TotalCost != test.cost {
+			t.Fatal(test.file, "optimum", s.TotalCost, "!=", test.cost)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package fsp
+
+import (
+	"context"
+	"math/rand"
+)
+
+type partial struct {
+	flights []*Flight
+	visited []bool
+	n       int
+	cost    Money
+	// entered sums bounds.areaIn of the areas the flights land in
+	areaIn  []Money
+	entered Money
+}
+
+func (p *partial) solution() Solution {
+	return Solution{p.flights, p.cost}
+}
+func (p *partial) roundtrip() bool {
+	ff := p.flights[0]
+	lf := p.lastFlight()
+	for _, ok := range p.visited {
+		if !ok {
+			return false
+		}
+	}
+	isHome := lf.ToArea == ff.FromArea
+	return isHome
+}
+func (p *partial) fly(f *Flight) {
+	p.visited[int(f.FromArea)] = true
+	p.flights = append(p.flights, f)
+	p.cost += f.Cost
+	p.entered += p.areaIn[f.ToArea]
+}
+func (p *partial) hasVisited(a Area) bool {
+	return p.visited[a]
+}
+func (p *partial) lastFlight() *Flight {
+	return p.flights[len(p.flights)-1]
+}
+func (p *partial) backtrack() {
+	f := p.flights[len(p.flights)-1]
+	p.visited[int(f.FromArea)] = false
+	p.flights = p.flights[0 : len(p.flights)-1]
+	p.cost -= f.Cost
+	p.entered -= p.areaIn[f.ToArea]
+}
+
+/*****************************************************************************/
+/* Greedy                                                                    */
+/*****************************************************************************/
+
+type Greedy struct {
+	problem     *Problem
+	graph       FlightIndices
+	bounds      *bounds
+	currentBest Money
+	finished    bool
+	endOnFirst  bool
+	// found is set once the search reached a tour, pruned once it cut a
+	// branch by currentBest, without either an exhaustive search proves
+	// that there is no tour
+	found  bool
+	pruned bool
+	// rng randomizes the candidate order, nil keeps the cheapest first
+	rng *rand.Rand
+	// maxNodes limits the search, 0 is unlimited
+	maxNodes int
+	nodes    int
+}
+
+func NewGreedy(problem *Problem) *Greedy {
+	return &Greedy{problem: problem, graph: problem.indices, bounds: problem.bounds(),
+		currentBest: unreachable}
+}
+
+func (d *Greedy) dfs(ctx context.Context, comm Comm, partial *partial) {
+	if d.finished {
+		return
+	}
+	d.nodes++
+	if d.maxNodes > 0 && d.nodes > d.maxNodes {
+		d.finished = true
+		return
+	}
+	if d.nodes&1023 == 0 && ctx.Err() != nil {
+		d.finished = true
+		return
+	}
+	if partial.cost > d.currentBest {
+		d.pruned = true
+		return
+	}
+	if partial.roundtrip() {
+		d.found = true
+		d.currentBest = comm.Send(partial.solution())
+		d.finished = d.currentBest == partial.cost && d.endOnFirst
+		return
+	}
+	lf := partial.lastFlight()
+	if partial.hasVisited(lf.ToArea) {
+		return
+	}
+	if partial.cost+d.bounds.rest(lf.Day, partial.entered) > d.currentBest {
+		d.pruned = true
+		return
+	}
+	// the last flight has to leave from the city we landed in as well, only
+	// its destination may be any city of the home area
+	dst := d.graph.fromDayTo.departures(lf.To, lf.Day+1)
+	if len(dst) == 0 {
+		return
+	}
+	off := 0
+	if d.rng != nil {
+		// start a few flights into the cheapest first order
+		off = d.rng.Intn(min(len(dst), 3))
+	}
+	for i := range dst {
+		partial.fly(&dst[(off+i)%len(dst)])
+		d.dfs(ctx, comm, partial)
+		partial.backtrack()
+	}
+}
+
+// search runs the depth first search from the start city on day 1, it
+// returns true when it searched every tour without cutting a branch and
+// found none, which proves that there is no tour
+func (d *Greedy) search(ctx context.Context, comm Comm) bool {
+	flights := make([]*Flight, 0, d.problem.length)
+	visited := make([]bool, d.problem.length, d.problem.length)
+	partial := partial{flights, visited, d.problem.length, 0, d.bounds.areaIn, 0}
+
+	dst := d.graph.fromDayTo.departures(d.problem.start, 1)
+	for i := range dst {
+		partial.fly(&dst[i])
+		d.dfs(ctx, comm, &partial)
+		partial.backtrack()
+	}
+	if !d.finished && !d.found && !d.pruned {
+		proveInfeasible(comm)
+		return true
+	}
+	return false
+}
+
+// Solve searches exhaustively on small instances, on the others it stops at
+// the first tour found.
+func (d Greedy) Solve(ctx context.Context, comm Comm) {
+	if len(d.problem.cityLookup.indexToName) > 10 {
+		d.endOnFirst = true
+	}
+	d.search(ctx, comm)
+}
+
+// GreedyRestarts repeats a randomized, node limited greedy search until ctx
+// is done, pruning by the global best of comm.
+type GreedyRestarts struct {
+	problem  *Problem
+	rng      *rand.Rand
+	maxNodes int
+	// iterations is the total number of search nodes, 0 runs until ctx is
+	// done
+	iterations int
+}
+
+func NewGreedyRestarts(problem *Problem, seed int64) *GreedyRestarts {
+	return &GreedyRestarts{problem: problem,
+		rng:      rand.New(rand.NewSource(seed)),
+		maxNodes: 1 << 20,
+	}
+}
+
+// SetIterations limits the restarts to n search nodes in total.
+func (r *GreedyRestarts) SetIterations(n int) {
+	r.iterations = n
+}
+
+func (r *GreedyRestarts) Solve(ctx context.Context, comm Comm) {
+	nodes := 0
+	for ctx.Err() == nil && (r.iterations == 0 || nodes < r.iterations) {
+		g := NewGreedy(r.problem)
+		g.endOnFirst = true
+		// until there is a first tour the search runs unlimited, cheapest first
+		if best := comm.Current(); len(best.Flights) > 0 {
+			g.currentBest, g.rng, g.maxNodes = best.TotalCost, r.rng, r.maxNodes
+			if r.iterations > 0 {
+				g.maxNodes = min(g.maxNodes, r.iterations-nodes)
+			}
+		}
+		proved := g.search(ctx, comm)
+		nodes += g.nodes
+		if proved {
+			return
+		}
+	}
+}
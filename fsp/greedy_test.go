@@ -0,0 +1,125 @@
+package fsp
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+type testcomm struct {
+	solution Solution
+}
+
+func (t *testcomm) Send(r Solution) Money {
+	if t.solution.Flights != nil && t.solution.TotalCost < r.TotalCost {
+		return t.solution.TotalCost
+	}
+	flights := make([]*Flight, len(r.Flights))
+	copy(flights, r.Flights)
+	t.solution = Solution{flights, r.TotalCost}
+	return r.TotalCost
+}
+func (t *testcomm) Current() Solution {
+	return t.solution
+}
+
+func eq(f1, f2 Flight) bool {
+	if f1.From != f2.From {
+		return false
+	}
+	if f1.To != f2.To {
+		return false
+	}
+	if f1.FromArea != f2.FromArea {
+		return false
+	}
+	if f1.ToArea != f2.ToArea {
+		return false
+	}
+	if f1.Day != f2.Day {
+		return false
+	}
+	if f1.Cost != f2.Cost {
+		return false
+	}
+	return true
+}
+
+func TestSolve(t *testing.T) {
+	input := `3 ASD
+Green
+ASD
+Red
+SKT
+Blue
+MXT GDO
+ASD MXT 1 50
+ASD GDO 1 10
+SKT ASD 0 30
+MXT SKT 2 20
+GDO SKT 2 90
+`
+	/*ASD := City(0)
+	SKT := City(1)
+	MXT := City(2)
+	GDO := City(3)
+	G := Area(0)
+	R := Area(1)
+	B := Area(2)
+
+	problem, err := ReadInput(strings.NewReader(input), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flights := []Flight{
+		{ASD, MXT, G, B, Day(1), 50, 0, 0.0},
+		{ASD, GDO, G, B, Day(1), 10, 0, 0.0},
+		{SKT, ASD, R, G, Day(0), 30, 0, 0.0},
+		{SKT, ASD, R, G, Day(1), 30, 0, 0.0},
+		{SKT, ASD, R, G, Day(2), 30, 0, 0.0},
+		{MXT, SKT, B, R, Day(2), 20, 0, 0.0},
+		{GDO, SKT, B, R, Day(2), 90, 0, 0.0},
+	}
+	expected := []*Flight{
+		&Flight{0, 1},
+	}*/
+	problem, err := ReadInput(strings.NewReader(input), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGreedy(problem)
+	c := &testcomm{}
+	g.Solve(context.Background(), c)
+	PrintSolution(os.Stdout, problem, c.solution)
+	if c.solution.TotalCost != 100 {
+		t.Fatalf("sample test cost %v != 100", c.solution.TotalCost)
+	}
+}
+
+func TestAreaSolve(t *testing.T) {
+	input := `3 ASD
+Green
+ASD TMP
+Red
+SKT
+Blue
+MXT GDO
+ASD MXT 1 50
+ASD GDO 1 10
+SKT TMP 0 30
+MXT SKT 2 20
+GDO SKT 2 90
+`
+	problem, err := ReadInput(strings.NewReader(input), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGreedy(problem)
+	c := &testcomm{}
+	g.Solve(context.Background(), c)
+	PrintSolution(os.Stdout, problem, c.solution)
+	if c.solution.TotalCost != 100 {
+		t.Fatalf("sample test cost %v != 100", c.solution.TotalCost)
+	}
+}
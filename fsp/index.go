@@ -0,0 +1,136 @@
+package fsp
+
+// Graph holds every flight in compressed sparse row form: a row is the
+// flights leaving one city on one day, rows are laid out day by day in one
+// flat block. Each row is sorted by cost and has a second view sorted by
+// destination for get.
+type Graph struct {
+	cities  int
+	days    int     // day 0 up to the day after the trip
+	offsets []int32 // row r spans [offsets[r], offsets[r+1])
+	flights []Flight
+	// table is an open addressing hash on (from, day, to) holding the index
+	// + 1 of the cheapest such flight, 0 marks an empty slot
+	table []int32
+	shift uint
+	next  []int32 // insertion point of each row while building
+}
+
+func (g *Graph) row(f City, d Day) (int32, int32) {
+	if int(f) >= g.cities || int(d) >= g.days {
+		return 0, 0
+	}
+	r := int(d)*g.cities + int(f)
+	return g.offsets[r], g.offsets[r+1]
+}
+
+// departures returns the flights leaving city f on day d, cheapest first
+func (g *Graph) departures(f City, d Day) []Flight {
+	lo, hi := g.row(f, d)
+	return g.flights[lo:hi]
+}
+
+// slot is where the search for flight (f, d, t) starts in Graph.table
+func (g *Graph) slot(f City, d Day, t City) uint64 {
+	key := uint64(f)<<32 | uint64(d)<<16 | uint64(t)
+	return key * 0x9e3779b97f4a7c15 >> g.shift
+}
+
+// get returns the cheapest flight from f to t on day d, nil if there is none
+func (g *Graph) get(f City, d Day, t City) *Flight {
+	mask := uint64(len(g.table) - 1)
+	for h := g.slot(f, d, t); ; h = (h + 1) & mask {
+		i := g.table[h]
+		if i == 0 {
+			return nil
+		}
+		fl := &g.flights[i-1]
+		if fl.To == t && fl.From == f && fl.Day == d {
+			return fl
+		}
+	}
+}
+
+type FlightIndices struct {
+	fromDayTo Graph
+}
+
+// newGraph returns an empty graph, it is filled in two passes over the
+// flights: count every flight, allocate, then add them in ascending cost
+// order and finish
+func newGraph(cities, length int) *Graph {
+	days := length + 2
+	return &Graph{cities: cities, days: days,
+		offsets: make([]int32, days*cities+1)}
+}
+
+func (g *Graph) count(from City, day Day) {
+	g.offsets[int(day)*g.cities+int(from)+1]++
+}
+
+func (g *Graph) allocate() {
+	for r := 1; r < len(g.offsets); r++ {
+		g.offsets[r] += g.offsets[r-1]
+	}
+	n := g.offsets[len(g.offsets)-1]
+	g.flights = make([]Flight, n)
+	g.next = make([]int32, len(g.offsets)-1)
+	copy(g.next, g.offsets)
+}
+
+// add places f behind the flights already added to its row, which keeps
+// rows sorted by cost
+func (g *Graph) add(f Flight) *Flight {
+	r := int(f.Day)*g.cities + int(f.From)
+	i := g.next[r]
+	g.next[r]++
+	g.flights[i] = f
+	return &g.flights[i]
+}
+
+// compact removes the flights without keep set, the rows stay sorted by cost,
+// and returns how many it removed
+func (g *Graph) compact(keep []bool) int {
+	n := int32(0)
+	for r := 0; r+1 < len(g.offsets); r++ {
+		lo, hi := g.offsets[r], g.offsets[r+1]
+		g.offsets[r] = n
+		for i := lo; i < hi; i++ {
+			if keep[i] {
+				g.flights[n] = g.flights[i]
+				n++
+			}
+		}
+	}
+	removed := len(g.flights) - int(n)
+	g.offsets[len(g.offsets)-1] = n
+	// let go of the space of the removed flights
+	g.flights = append([]Flight(nil), g.flights[:n]...)
+	g.finish()
+	return removed
+}
+
+// finish builds the hash table, visiting rows in cost order leaves the
+// cheapest of parallel flights in it
+func (g *Graph) finish() {
+	size := 2
+	for g.shift = 63; size < 2*len(g.flights); g.shift-- {
+		size <<= 1
+	}
+	g.table = make([]int32, size)
+	mask := uint64(size - 1)
+	for i := range g.flights {
+		f := &g.flights[i]
+		for h := g.slot(f.From, f.Day, f.To); ; h = (h + 1) & mask {
+			j := g.table[h]
+			if j == 0 {
+				g.table[h] = int32(i + 1)
+				break
+			}
+			if o := &g.flights[j-1]; o.To == f.To && o.From == f.From && o.Day == f.Day {
+				break
+			}
+		}
+	}
+	g.next = nil
+}
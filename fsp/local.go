@@ -0,0 +1,120 @@
+package fsp
+
+import (
+	"context"
+	"math/rand"
+)
+
+/*****************************************************************************/
+/* Local search                                                              */
+/*****************************************************************************/
+
+// LocalSearch is an iterated best improvement descent over swapFlights,
+// swapInArea and the segment moves, kicked by a few random moves whenever it
+// gets stuck.
+type LocalSearch struct {
+	problem *Problem
+	rng     *rand.Rand
+	kicks   int
+	// iterations is the number of candidate moves to evaluate, 0 runs until
+	// ctx is done
+	iterations int
+	evaluated  int
+}
+
+func NewLocalSearch(problem *Problem, seed int64) *LocalSearch {
+	return &LocalSearch{problem: problem,
+		rng:   rand.New(rand.NewSource(seed)),
+		kicks: 3,
+	}
+}
+
+// SetIterations limits the search to about n evaluated moves.
+func (l *LocalSearch) SetIterations(n int) {
+	l.iterations = n
+}
+
+func (l *LocalSearch) spent(ctx context.Context) bool {
+	if l.iterations > 0 {
+		return l.evaluated >= l.iterations
+	}
+	return ctx.Err() != nil
+}
+
+func (l *LocalSearch) Solve(ctx context.Context, comm Comm) {
+	best, ok := waitForTour(ctx, comm, l.iterations == 0)
+	if !ok {
+		return
+	}
+	current := Solution{make([]*Flight, len(best.Flights)), best.TotalCost}
+	copy(current.Flights, best.Flights)
+	deltas := newDeltaCache(&current, l.problem.indices.fromDayTo, l.problem.areaDb)
+	for !l.spent(ctx) {
+		copy(current.Flights, best.Flights)
+		current.TotalCost = best.TotalCost
+		l.kick(&current)
+		l.descend(ctx, &current, deltas)
+		if current.TotalCost < best.TotalCost {
+			copy(best.Flights, current.Flights)
+			best.TotalCost = current.TotalCost
+			logln(Verbose, "local search new solution", best.TotalCost)
+			comm.Send(best)
+		}
+		// continue from the global best when another solver did better
+		if global := comm.Current(); global.TotalCost < best.TotalCost {
+			best = global
+		}
+	}
+}
+
+// descend applies the best improving move until there is none, deltas
+// caches the swap moves of s
+func (l *LocalSearch) descend(ctx context.Context, s *Solution, deltas *deltaCache) {
+	g := l.problem.indices.fromDayTo
+	n := len(s.Flights)
+	for improved := true; improved && !l.spent(ctx); {
+		improved = false
+		l.evaluated += n*n/2 + n
+		i, j := deltas.bestFlightSwap()
+		if ok, newCost := swapFlights(*s, g, i, j, false); ok && newCost < s.TotalCost {
+			swapFlights(*s, g, i, j, true)
+			s.TotalCost, improved = newCost, true
+		}
+		fi, ci := deltas.bestAreaSwap()
+		if ok, newCost := swapInArea(*s, g, fi, ci, false); ok && newCost < s.TotalCost {
+			swapInArea(*s, g, fi, ci, true)
+			s.TotalCost, improved = newCost, true
+		}
+		m, tried, ok := bestSegmentMove(ctx, *s, g, n)
+		l.evaluated += tried
+		if ok {
+			if _, newCost := m.try(*s, g, false); newCost < s.TotalCost {
+				m.try(*s, g, true)
+				s.TotalCost, improved = newCost, true
+			}
+		}
+	}
+}
+
+// kick applies a few random feasible moves regardless of their cost
+func (l *LocalSearch) kick(s *Solution) {
+	g := l.problem.indices.fromDayTo
+	n := len(s.Flights)
+	l.evaluated += l.kicks
+	for k := 0; k < l.kicks; k++ {
+		if n-2 > 1 {
+			i, j := randomFlightSwap(l.rng, n-2)
+			if ok, newCost := swapFlights(*s, g, i, j, false); ok {
+				swapFlights(*s, g, i, j, true)
+				s.TotalCost = newCost
+			}
+		}
+		if n-1 > 1 {
+			fi, ci := randomAreaSwap(l.rng, n-1, s.Flights, l.problem.areaDb)
+			if ok, newCost := swapInArea(*s, g, fi, ci, false); ok {
+				swapInArea(*s, g, fi, ci, true)
+				s.TotalCost = newCost
+			}
+		}
+	}
+}
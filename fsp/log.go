@@ -0,0 +1,32 @@
+package fsp
+
+import (
+	"log"
+	"os"
+)
+
+// Verbosity selects how much the solvers report on Logger.
+type Verbosity int
+
+const (
+	Quiet   Verbosity = iota // nothing
+	Normal                   // warnings and invalid solutions
+	Verbose                  // progress of the search
+)
+
+// Logger receives the solver chatter up to Level, both are meant to be set
+// once before solving starts.
+var Logger = log.New(os.Stderr, "", 0)
+var Level = Normal
+
+func logf(v Verbosity, format string, args ...interface{}) {
+	if v <= Level {
+		Logger.Printf(format, args...)
+	}
+}
+
+func logln(v Verbosity, args ...interface{}) {
+	if v <= Level {
+		Logger.Println(args...)
+	}
+}
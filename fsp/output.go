@@ -0,0 +1,115 @@
+package fsp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Output formats accepted by WriteSolution.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// PrintSolution writes the solution in the contest format, total cost on the
+// first line followed by one "FROM TO DAY PRICE" line per flight.
+func PrintSolution(w io.Writer, p *Problem, s Solution) {
+	fmt.Fprintln(w, s.TotalCost)
+	for _, f := range s.Flights {
+		fmt.Fprintln(w, p.cityLookup.indexToName[f.From],
+			p.cityLookup.indexToName[f.To],
+			f.Day,
+			f.Cost,
+		)
+	}
+}
+
+type jsonLeg struct {
+	Day      Day    `json:"day"`
+	From     string `json:"from"`
+	FromArea string `json:"from_area"`
+	To       string `json:"to"`
+	ToArea   string `json:"to_area"`
+	Price    Money  `json:"price"`
+}
+
+type jsonSolver struct {
+	Name         string  `json:"name"`
+	ElapsedMs    float64 `json:"elapsed_ms"`
+	Improvements int     `json:"improvements"`
+	Seed         int64   `json:"seed"`
+}
+
+type jsonSolution struct {
+	Status     string     `json:"status"`
+	TotalCost  Money      `json:"total_cost"`
+	LowerBound Money      `json:"lower_bound,omitempty"`
+	Currency   string     `json:"currency"`
+	Legs       []jsonLeg  `json:"legs"`
+	Solver     jsonSolver `json:"solver"`
+}
+
+// WriteJSON writes the solution with city and area names of every leg and
+// the solver metadata as one JSON document.
+func WriteJSON(w io.Writer, p *Problem, s Solution, meta Meta, currency string) error {
+	doc := jsonSolution{
+		Status:     meta.Outcome.String(),
+		TotalCost:  s.TotalCost,
+		LowerBound: meta.LowerBound,
+		Currency:   currency,
+		Legs:       make([]jsonLeg, 0, len(s.Flights)),
+		Solver: jsonSolver{meta.Solver,
+			float64(meta.Elapsed.Nanoseconds()) / 1e6, meta.Improvements, meta.Seed},
+	}
+	if len(s.Flights) == 0 {
+		// the comm starts from a placeholder cost
+		doc.TotalCost = 0
+	}
+	for _, f := range s.Flights {
+		doc.Legs = append(doc.Legs, jsonLeg{f.Day,
+			p.CityName(f.From), p.AreaName(f.FromArea),
+			p.CityName(f.To), p.AreaName(f.ToArea), f.Cost})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// WriteCSV writes one row per leg under a header row.
+func WriteCSV(w io.Writer, p *Problem, s Solution, currency string) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"day", "from", "from_area", "to", "to_area", "price", "currency"})
+	for _, f := range s.Flights {
+		out.Write([]string{strconv.Itoa(int(f.Day)),
+			p.CityName(f.From), p.AreaName(f.FromArea),
+			p.CityName(f.To), p.AreaName(f.ToArea),
+			strconv.FormatUint(uint64(f.Cost), 10), currency})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteSolution writes the solution in one of the Format* formats. Without a
+// tour the text format is the single line meta.Outcome, json has no legs and
+// csv just the header.
+func WriteSolution(w io.Writer, format string, p *Problem, s Solution, meta Meta, currency string) error {
+	switch format {
+	case FormatText:
+		if len(s.Flights) == 0 {
+			// there is no cost to print, say why
+			_, err := fmt.Fprintln(w, meta.Outcome)
+			return err
+		}
+		PrintSolution(w, p, s)
+		return nil
+	case FormatJSON:
+		return WriteJSON(w, p, s, meta, currency)
+	case FormatCSV:
+		return WriteCSV(w, p, s, currency)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
@@ -0,0 +1,105 @@
+package fsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func solvedSample(t *testing.T) (*Problem, *SolutionComm) {
+	problem, err := ReadInput(strings.NewReader(sampleHeader+`ASD MXT 1 50
+ASD GDO 1 10
+SKT ASD 0 30
+MXT SKT 2 20
+GDO SKT 2 90
+`), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	c := NewComm(problem)
+	c.Run(ctx, "exact", NewExact(problem))
+	if !c.Wait(ctx, time.Second) {
+		t.Fatal("exact did not exit")
+	}
+	return problem, c
+}
+
+func TestWriteJSON(t *testing.T) {
+	problem, c := solvedSample(t)
+	var b bytes.Buffer
+	if err := WriteSolution(&b, FormatJSON, problem, c.Current(), c.Meta(), "EUR"); err != nil {
+		t.Fatal(err)
+	}
+	var doc jsonSolution
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Status != "found" || doc.TotalCost != 100 || doc.Currency != "EUR" || len(doc.Legs) != 3 {
+		t.Fatal("unexpected document", b.String())
+	}
+	if doc.Legs[1] != (jsonLeg{2, "MXT", "Blue", "SKT", "Red", 20}) {
+		t.Fatal("leg mismatch", doc.Legs[1])
+	}
+	if doc.Solver.Name != "exact" || doc.Solver.Improvements != 1 {
+		t.Fatal("solver metadata mismatch", doc.Solver)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	problem, c := solvedSample(t)
+	var b bytes.Buffer
+	if err := WriteSolution(&b, FormatCSV, problem, c.Current(), c.Meta(), "EUR"); err != nil {
+		t.Fatal(err)
+	}
+	expected := `day,from,from_area,to,to_area,price,currency
+1,ASD,Green,MXT,Blue,50,EUR
+2,MXT,Blue,SKT,Red,20,EUR
+3,SKT,Red,ASD,Green,30,EUR
+`
+	if b.String() != expected {
+		t.Fatal("csv mismatch\n", b.String())
+	}
+	if err := WriteSolution(&b, "xml", problem, c.Current(), c.Meta(), "EUR"); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
+
+func TestWriteNoTour(t *testing.T) {
+	problem, _ := solvedSample(t)
+	meta := Meta{Outcome: Infeasible}
+	var b bytes.Buffer
+	if err := WriteSolution(&b, FormatText, problem, Solution{}, meta, "EUR"); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "infeasible\n" {
+		t.Fatal("text mismatch", b.String())
+	}
+	b.Reset()
+	meta.Outcome = NotFound
+	if err := WriteSolution(&b, FormatJSON, problem, NewComm(problem).Current(), meta, "EUR"); err != nil {
+		t.Fatal(err)
+	}
+	var doc jsonSolution
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Status != "not found" || doc.TotalCost != 0 || len(doc.Legs) != 0 {
+		t.Fatal("unexpected document", b.String())
+	}
+}
+
+func TestTieKeepsAttribution(t *testing.T) {
+	_, c := solvedSample(t)
+	before := c.Meta()
+	if cost := WithSolver(c, "other").Send(c.Current()); cost != 100 {
+		t.Fatal("tie changed the best cost", cost)
+	}
+	if meta := c.Meta(); meta != before || meta.Solver != "exact" {
+		t.Fatal("tie changed the metadata", meta)
+	}
+}
@@ -0,0 +1,433 @@
+package fsp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// codeSpace is the number of distinct three character [A-Z0-9] codes
+const codeSpace = 36 * 36 * 36
+
+// codeKey maps a three character code to its slot in LookupC.codeToIndex
+func codeKey(code []byte) (int, bool) {
+	if len(code) != 3 {
+		return 0, false
+	}
+	key := 0
+	for _, c := range code {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			key = key*36 + int(c-'A')
+		case c >= '0' && c <= '9':
+			key = key*36 + 26 + int(c-'0')
+		default:
+			return 0, false
+		}
+	}
+	return key, true
+}
+
+func isCode(s []byte) bool {
+	_, ok := codeKey(s)
+	return ok
+}
+
+func cityIndex(city []byte, l *LookupC) City {
+	/* get index of city in lookup table or put it in the table and get index */
+	key, _ := codeKey(city)
+	if ci := l.codeToIndex[key]; ci != 0 {
+		return ci - 1
+	}
+	ci := City(len(l.indexToName))
+	l.codeToIndex[key] = ci + 1
+	l.indexToName = append(l.indexToName, string(city))
+	return ci
+}
+
+func areaIndex(area string, l *LookupA) Area {
+	ai, found := l.nameToIndex[area]
+	if found {
+		return ai
+	}
+	ai = Area(len(l.nameToIndex))
+	l.nameToIndex[area] = ai
+	l.indexToName = append(l.indexToName, area)
+	return ai
+}
+
+// ParseError describes a malformed input line.
+type ParseError struct {
+	Line int
+	Text string
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s: %q", e.Line, e.Msg, e.Text)
+}
+
+// lineReader hands out input lines without copying them, line is only valid
+// until the next call of next
+type lineReader struct {
+	r    *bufio.Reader
+	n    int
+	line []byte
+	err  error
+}
+
+func (r *lineReader) next() bool {
+	line, err := r.r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		r.err = &ParseError{r.n + 1, string(line[:80]), "line too long"}
+		return false
+	}
+	if err != nil && err != io.EOF {
+		r.err = err
+		return false
+	}
+	if len(line) == 0 {
+		return false
+	}
+	r.n++
+	if line[len(line)-1] == '\n' {
+		line = line[:len(line)-1]
+	}
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	r.line = line
+	return true
+}
+
+func (r *lineReader) errorf(format string, args ...interface{}) *ParseError {
+	return &ParseError{r.n, string(r.line), fmt.Sprintf(format, args...)}
+}
+
+// expect reads the next line, failing with a message about what was expected
+func (r *lineReader) expect(what string) error {
+	if r.next() {
+		return nil
+	}
+	if r.err != nil {
+		return r.err
+	}
+	return &ParseError{r.n + 1, "", "unexpected end of input, expected " + what}
+}
+
+// atoi parses a non-empty run of decimal digits not exceeding max
+func atoi(s []byte, max uint64) (uint64, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+	var n uint64
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + uint64(c-'0')
+		if n > max {
+			return 0, false
+		}
+	}
+	return n, true
+}
+
+// parseFlight validates the fields of a flight line and resolves its cities,
+// strictly expects format "{3}[A-Z0-9] {3}[A-Z0-9] \d \d" with the price up
+// to maxPrice
+func parseFlight(r *lineReader, length int, maxPrice uint64, lookupC *LookupC) (City, City, Day, Money, error) {
+	s := r.line
+	if len(s) < 11 || s[3] != ' ' || s[7] != ' ' {
+		return 0, 0, 0, 0, r.errorf("expected \"FROM TO DAY PRICE\"")
+	}
+	var cities [2]City
+	for i, code := range [][]byte{s[:3], s[4:7]} {
+		key, ok := codeKey(code)
+		if !ok {
+			return 0, 0, 0, 0, r.errorf("city code %q is not three characters A-Z0-9", code)
+		}
+		if lookupC.codeToIndex[key] == 0 {
+			return 0, 0, 0, 0, r.errorf("city %s does not belong to any area", code)
+		}
+		cities[i] = lookupC.codeToIndex[key] - 1
+	}
+	sp := bytes.IndexByte(s[8:], ' ')
+	if sp < 0 {
+		return 0, 0, 0, 0, r.errorf("expected \"FROM TO DAY PRICE\"")
+	}
+	dayField, priceField := s[8:8+sp], s[9+sp:]
+	day, ok := atoi(dayField, 1<<16-1)
+	if !ok {
+		return 0, 0, 0, 0, r.errorf("invalid day %q", dayField)
+	}
+	if int(day) > length {
+		return 0, 0, 0, 0, r.errorf("day %v is beyond the trip length %v", day, length)
+	}
+	if len(priceField) > 0 && priceField[0] == '-' {
+		return 0, 0, 0, 0, r.errorf("negative price %s", priceField)
+	}
+	cost, ok := atoi(priceField, maxPrice)
+	if !ok {
+		return 0, 0, 0, 0, r.errorf("invalid price %q or above %v, the tour total could overflow", priceField, maxPrice)
+	}
+	return cities[0], cities[1], Day(day), Money(cost), nil
+}
+
+// flightArena keeps the flight lines column-wise while parsing, a day 0
+// flight is kept as one record and expanded when the problem is built
+type flightArena struct {
+	from []City
+	to   []City
+	day  []Day
+	cost []Money
+}
+
+func (a *flightArena) add(from, to City, day Day, cost Money) {
+	a.from = append(a.from, from)
+	a.to = append(a.to, to)
+	a.day = append(a.day, day)
+	a.cost = append(a.cost, cost)
+}
+
+// days returns the first and last day the i-th record is worth flying on,
+// first > last when it is useless
+func (a *flightArena) days(i int, areaOf []Area, length int) (Day, Day) {
+	if d := a.day[i]; d != 0 {
+		switch {
+		case a.from[i] == City(0) && d > 1:
+			// the tour leaves the start city on the first day only
+			return 1, 0
+		case d == 1 && a.from[i] != City(0):
+			// and only the start city on the first day
+			return 1, 0
+		case d != Day(length) && areaOf[a.to[i]] == areaOf[City(0)]:
+			// it returns to the home area on the last day only
+			return 1, 0
+		}
+		return d, d
+	}
+	// this flight takes place on every day
+	first, last := Day(2), Day(length)
+	if a.from[i] == City(0) {
+		first, last = 1, 1
+	}
+	if areaOf[a.to[i]] == areaOf[City(0)] && first < Day(length) {
+		first = Day(length)
+	}
+	return first, last
+}
+
+// ReadInput parses the problem in the contest format and builds the flight
+// indices. Malformed input is reported as *ParseError with the line number.
+// In lenient mode malformed flight lines are skipped and counted in
+// Problem.Skipped, header and area errors are always fatal.
+func ReadInput(input io.Reader, lenient bool) (*Problem, error) {
+	lookupC := &LookupC{}
+	lookupA := &LookupA{make(map[string]Area), nil}
+	areaDb := &AreaDb{make(map[City]Area), make(map[Area][]City)}
+	arena := &flightArena{}
+	r := &lineReader{r: bufio.NewReaderSize(input, 1<<16)}
+
+	var src []byte
+	var timeLimit time.Duration
+	var length, skipped int
+	var from, to City
+	var day Day
+	var cost Money
+	var err error
+	// read first line
+	if err = r.expect("header \"LENGTH START\""); err != nil {
+		return nil, err
+	}
+	firstLine := bytes.Split(r.line, []byte{' '})
+	if len(firstLine) != 2 {
+		return nil, r.errorf("expected header \"LENGTH START\"")
+	}
+	length, err = strconv.Atoi(string(firstLine[0]))
+	if err != nil || length < 1 {
+		return nil, r.errorf("invalid trip length %q", firstLine[0])
+	}
+	if length > MAX_LENGTH {
+		return nil, r.errorf("trip length %v exceeds %v areas", length, MAX_LENGTH)
+	}
+	src = firstLine[1]
+	if !isCode(src) {
+		return nil, r.errorf("city code %q is not three characters A-Z0-9", src)
+	}
+	cityIndex(src, lookupC)
+	// read areas
+	var areaId Area
+	var cityId City
+	for i := 0; i < length; i++ {
+		if err = r.expect(fmt.Sprintf("name of area %v", i)); err != nil {
+			return nil, err
+		}
+		area := string(r.line)
+		if area == "" {
+			return nil, r.errorf("empty area name")
+		}
+		if _, found := lookupA.nameToIndex[area]; found {
+			return nil, r.errorf("duplicate area %v", area)
+		}
+		areaId = areaIndex(area, lookupA)
+		if err = r.expect(fmt.Sprintf("cities of area %v", area)); err != nil {
+			return nil, err
+		}
+		cities := bytes.Split(r.line, []byte{' '})
+		cityIds := make([]City, 0, len(cities))
+		for _, src := range cities {
+			if !isCode(src) {
+				return nil, r.errorf("city code %q is not three characters A-Z0-9", src)
+			}
+			cityId = cityIndex(src, lookupC)
+			if a, found := areaDb.cityToArea[cityId]; found {
+				return nil, r.errorf("city %s already belongs to area %v", src, lookupA.indexToName[a])
+			}
+			areaDb.cityToArea[cityId] = areaId
+			cityIds = append(cityIds, cityId)
+		}
+		areaDb.areaToCities[areaId] = cityIds
+
+	}
+	if _, found := areaDb.cityToArea[City(0)]; !found {
+		return nil, &ParseError{1, lookupC.indexToName[0], "start city does not belong to any area"}
+	}
+	homeArea := areaDb.cityToArea[City(0)]
+	// read flights
+	for r.next() {
+		if len(r.line) == 0 {
+			continue
+		}
+		from, to, day, cost, err = parseFlight(r, length, priceLimit(length), lookupC)
+		if err != nil {
+			if lenient {
+				skipped++
+				continue
+			}
+			return nil, err
+		}
+		arena.add(from, to, day, cost)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if length <= 20 {
+		timeLimit = 3 * time.Second
+	} else if length <= 100 {
+		timeLimit = 5 * time.Second
+	} else {
+		timeLimit = 15 * time.Second
+	}
+
+	flights, indices := buildIndices(arena, areaDb, len(lookupC.indexToName), length)
+	p := &Problem{flights: flights, indices: *indices, areaDb: *areaDb,
+		areaLookup: *lookupA, cityLookup: *lookupC, start: City(0), goal: homeArea,
+		length: length, timeLimit: timeLimit, skipped: skipped, input: arena}
+	p.census = newCensus(p)
+	p.pruned = p.prune()
+	return p, nil
+}
+
+// buildIndices expands the arena into the flight graph, records are
+// expanded cheapest first so that its rows come out sorted by cost without
+// sorting every row
+func buildIndices(arena *flightArena, areaDb *AreaDb, cities, length int) ([]Flight, *FlightIndices) {
+	areaOf := make([]Area, cities)
+	for c, a := range areaDb.cityToArea {
+		areaOf[c] = a
+	}
+	g := newGraph(cities, length)
+	byPrice := make([]int, 0, len(arena.day))
+	for i := range arena.day {
+		first, last := arena.days(i, areaOf, length)
+		for d := first; d <= last; d++ {
+			g.count(arena.from[i], d)
+		}
+		if first <= last {
+			byPrice = append(byPrice, i)
+		}
+	}
+	sort.SliceStable(byPrice, func(a, b int) bool {
+		return arena.cost[byPrice[a]] < arena.cost[byPrice[b]]
+	})
+	g.allocate()
+	for _, i := range byPrice {
+		from, to := arena.from[i], arena.to[i]
+		first, last := arena.days(i, areaOf, length)
+		for d := first; d <= last; d++ {
+			g.add(Flight{from, to, areaOf[from], areaOf[to], d, arena.cost[i], 0, 0.0})
+		}
+	}
+	g.finish()
+	return g.flights, &FlightIndices{*g}
+}
+
+// ReadSolution parses a solution in the PrintSolution format and resolves
+// every line against the flights of the problem. A line that matches no
+// flight of the graph is kept as a flight of its own, Validate reports it
+// when it is not in the input either. TotalCost is the cost stated on the
+// first line.
+func ReadSolution(input io.Reader, p *Problem) (Solution, error) {
+	r := &lineReader{r: bufio.NewReader(input)}
+	if err := r.expect("total cost"); err != nil {
+		return Solution{}, err
+	}
+	total, ok := atoi(bytes.TrimSpace(r.line), 1<<32-1)
+	if !ok {
+		return Solution{}, r.errorf("invalid total cost")
+	}
+	s := Solution{TotalCost: Money(total)}
+	for r.next() {
+		if len(r.line) == 0 {
+			continue
+		}
+		from, to, day, cost, err := parseFlight(r, MAX_LENGTH, priceLimit(p.length), &p.cityLookup)
+		if err != nil {
+			return Solution{}, err
+		}
+		s.Flights = append(s.Flights, p.resolve(from, to, day, cost))
+	}
+	if r.err != nil {
+		return Solution{}, r.err
+	}
+	return s, nil
+}
+
+// resolve finds the flight of the graph with the given price
+func (p *Problem) resolve(from, to City, day Day, cost Money) *Flight {
+	dst := p.indices.fromDayTo.departures(from, day)
+	for i := range dst {
+		if dst[i].To == to && dst[i].Cost == cost {
+			return &dst[i]
+		}
+	}
+	return &Flight{from, to, p.areaDb.cityToArea[from], p.areaDb.cityToArea[to], day, cost, 0, 0.0}
+}
+
+// has reports whether the arena holds the flight line, a day 0 record is on
+// every day
+func (a *flightArena) has(from, to City, day Day, cost Money) bool {
+	for i := range a.from {
+		if a.from[i] == from && a.to[i] == to && a.cost[i] == cost && (a.day[i] == day || a.day[i] == 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// cheapest returns the lowest price of the flight lines from from to to on
+// day, false when there is none
+func (a *flightArena) cheapest(from, to City, day Day) (Money, bool) {
+	best, found := Money(0), false
+	for i := range a.from {
+		if a.from[i] == from && a.to[i] == to && (a.day[i] == day || a.day[i] == 0) && (!found || a.cost[i] < best) {
+			best, found = a.cost[i], true
+		}
+	}
+	return best, found
+}
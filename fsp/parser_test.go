@@ -0,0 +1,168 @@
+package fsp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const sampleHeader = `3 ASD
+Green
+ASD
+Red
+SKT
+Blue
+MXT GDO
+`
+
+func TestReadInputErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		line  int
+	}{
+		{input: "", line: 1},
+		{input: "3\n", line: 1},
+		{input: "x ASD\n", line: 1},
+		{input: "3 asd\n", line: 1},
+		{input: "3 ASD\nGreen\n", line: 3},
+		{input: "3 ASD\nGreen\nASD XY\n", line: 3},
+		{input: "2 ASD\nGreen\nASD\nRed\nASD\n", line: 5},
+		{input: "1 ASD\nGreen\nSKT\n", line: 1},
+		{input: sampleHeader + "ASD MXT 1\n", line: 8},
+		{input: sampleHeader + "ASD MXT1 1 50\n", line: 8},
+		{input: sampleHeader + "ASD XXX 1 50\n", line: 8},
+		{input: sampleHeader + "ASD MXT x 50\n", line: 8},
+		{input: sampleHeader + "ASD MXT 4 50\n", line: 8},
+		{input: sampleHeader + "ASD MXT 1 -50\n", line: 8},
+		{input: sampleHeader + "ASD MXT 1 50\nASD MXT 1 4294967296\n", line: 9},
+		// three such flights would overflow the total
+		{input: sampleHeader + "ASD MXT 1 1431655765\n", line: 8},
+		{input: "2 AAA\nH\nAAA\nB\nBBB\nAAA BBB 1 3000000000\nBBB AAA 2 5\n", line: 6},
+		{input: "65535 ASD\n", line: 1},
+	}
+	for ti, test := range tests {
+		_, err := ReadInput(strings.NewReader(test.input), false)
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Fatal(ti, "expected ParseError, got", err)
+		}
+		if perr.Line != test.line {
+			t.Fatal(ti, "line mismatch", perr)
+		}
+	}
+}
+
+func TestReadInputLenient(t *testing.T) {
+	input := sampleHeader + `ASD MXT 1 50
+ASD GDO 1 10
+ASD XXX 1 10
+SKT ASD 0 30
+SKT ASD 9 30
+MXT SKT 2 20
+GDO SKT 2 -90
+`
+	if _, err := ReadInput(strings.NewReader(input), false); err == nil {
+		t.Fatal("strict mode accepted malformed input")
+	}
+	problem, err := ReadInput(strings.NewReader(input), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problem.Skipped() != 3 {
+		t.Fatal("skipped", problem.Skipped(), "!= 3")
+	}
+}
+
+// chain builds a trip of n areas with two cities each, which is only
+// feasible along the flights city i -> city i+1 on day i+1
+func chain(n int) string {
+	code := func(i int) string {
+		return string([]byte{byte('A' + i/26/26%26), byte('A' + i/26%26), byte('A' + i%26)})
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s\n", n, code(0))
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "area%d\n%s %s\n", i, code(2*i), code(2*i+1))
+	}
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "%s %s %d %d\n", code(2*i), code(2*(i+1)%(2*n)), i+1, i+10)
+	}
+	return b.String()
+}
+
+func TestReadInputLarge(t *testing.T) {
+	// more cities and days than the old fixed size indices had room for
+	n := 400
+	problem, err := ReadInput(strings.NewReader(chain(n)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &testcomm{}
+	NewGreedy(problem).Solve(context.Background(), c)
+	if len(c.solution.Flights) != n || c.solution.TotalCost != Money(n*(n+1)/2+9*n) {
+		t.Fatal("chain tour", len(c.solution.Flights), c.solution.TotalCost)
+	}
+}
+
+func TestReadSolution(t *testing.T) {
+	problem, err := ReadInput(strings.NewReader(chain(20)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &testcomm{}
+	NewGreedy(problem).Solve(context.Background(), c)
+	var b bytes.Buffer
+	PrintSolution(&b, problem, c.solution)
+	s, err := ReadSolution(&b, problem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s, c.solution) {
+		t.Fatal("read back", s, "!=", c.solution)
+	}
+
+	for ti, test := range []struct {
+		input string
+		line  int
+	}{
+		{input: "", line: 1},
+		{input: "x\n", line: 1},
+		{input: "10\nAAA AAC 1\n", line: 2},
+		{input: "10\nAAA XXX 1 10\n", line: 2},
+	} {
+		_, err := ReadSolution(strings.NewReader(test.input), problem)
+		if perr, ok := err.(*ParseError); !ok || perr.Line != test.line {
+			t.Fatal(ti, "expected ParseError on line", test.line, "got", err)
+		}
+	}
+}
+
+func BenchmarkReadInput(b *testing.B) {
+	data, err := ioutil.ReadFile("../data/2.in")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	var problem *Problem
+	for i := 0; i < b.N; i++ {
+		if problem, err = ReadInput(bytes.NewReader(data), false); err != nil {
+			b.Fatal(err)
+		}
+	}
+	// heap still in use by the last problem, mostly its indices
+	b.StopTimer()
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(problem)
+	problem = nil
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/(1<<20), "live-MB")
+}
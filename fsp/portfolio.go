@@ -0,0 +1,197 @@
+package fsp
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+/*****************************************************************************/
+/* Portfolio                                                                 */
+/*****************************************************************************/
+
+// DefaultMix is the worker mix used when none is given, cycled over workers.
+var DefaultMix = []string{"greedy", "sa", "sa", "local"}
+
+var coolings = []string{"geometric", "adaptive", "lundy-mees", "linear"}
+var heats = []float64{1, 0.5, 2, 4}
+
+// Portfolio runs independent solvers in parallel, all publishing into one
+// comm and pulling the global best from it.
+type Portfolio struct {
+	problem    *Problem
+	workers    []Solver
+	names      []string
+	iterations int
+	sas        int // sa workers created, picks their cooling
+}
+
+// NewPortfolio creates n workers (GOMAXPROCS when n <= 0) of the kinds in
+// mix: "greedy" restarts, "beam", "sa", "tabu" and "local" search. Worker 0 is
+// always greedy as the others improve tours and need a first one. A single
+// worker builds a greedy tour and improves it with the first improver of mix,
+// sa if there is none. Worker i is seeded with DeriveSeed(seed, i).
+func NewPortfolio(problem *Problem, n int, mix []string, seed int64) (*Portfolio, error) {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	if len(mix) == 0 {
+		mix = DefaultMix
+	}
+	p := &Portfolio{problem: problem}
+	for _, kind := range mix {
+		if !knownKind(kind) {
+			return nil, fmt.Errorf("unknown solver %q in portfolio mix", kind)
+		}
+	}
+	if n == 1 {
+		kind := "sa"
+		for _, k := range mix {
+			if k == "sa" || k == "tabu" || k == "local" {
+				kind = k
+				break
+			}
+		}
+		w := p.worker(kind, 0, seed)
+		p.workers = []Solver{Sequence(Named("greedy", NewGreedy(problem)), w)}
+		p.names = []string{fmt.Sprintf("%v#0", kind)}
+		return p, nil
+	}
+	for i := 0; i < n; i++ {
+		kind := mix[i%len(mix)]
+		if i == 0 {
+			kind = "greedy"
+		}
+		w := p.worker(kind, i, seed)
+		p.workers = append(p.workers, w)
+		p.names = append(p.names, fmt.Sprintf("%v#%v", kind, i))
+	}
+	return p, nil
+}
+
+func knownKind(kind string) bool {
+	switch kind {
+	case "greedy", "sa", "local", "beam", "tabu":
+		return true
+	}
+	return false
+}
+
+// worker creates worker i of a known kind
+func (p *Portfolio) worker(kind string, i int, seed int64) Solver {
+	problem := p.problem
+	switch kind {
+	case "greedy":
+		return NewGreedyRestarts(problem, DeriveSeed(seed, i))
+	case "sa":
+		sa := NewSA(problem, DeriveSeed(seed, i))
+		sa.syncEvery = 10000
+		sa.cooling, sa.heat = coolings[p.sas%len(coolings)], heats[p.sas%len(heats)]
+		p.sas++
+		return sa
+	case "local":
+		return NewLocalSearch(problem, DeriveSeed(seed, i))
+	case "beam":
+		return NewBeam(problem, DefaultBeamWidth)
+	case "tabu":
+		tabu := NewTabu(problem, DeriveSeed(seed, i))
+		tabu.syncEvery = 1000
+		return tabu
+	}
+	panic("unknown portfolio worker " + kind)
+}
+
+// SetIterations gives every worker a budget of n iterations and runs them one
+// after another in a fixed order, so that the result only depends on the
+// seed.
+func (p *Portfolio) SetIterations(n int) {
+	p.iterations = n
+	for _, w := range p.workers {
+		if b, ok := w.(Budgeted); ok {
+			b.SetIterations(n)
+		}
+	}
+}
+
+// Solve returns once every worker has exited, a worker that panics is
+// restarted or retired without taking the others down.
+func (p *Portfolio) Solve(ctx context.Context, comm Comm) {
+	if p.iterations > 0 {
+		for i, w := range p.workers {
+			supervise(ctx, WithSolver(comm, p.names[i]), p.names[i], w)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for i, w := range p.workers {
+		wg.Add(1)
+		go func(w Solver, name string) {
+			defer wg.Done()
+			supervise(ctx, WithSolver(comm, name), name, w)
+		}(w, p.names[i])
+	}
+	wg.Wait()
+}
+
+// Sequence runs the solvers one after another until ctx is done, e.g. a
+// constructor followed by an improvement heuristic.
+func Sequence(solvers ...Solver) Solver {
+	return sequence(solvers)
+}
+
+type sequence []Solver
+
+func (s sequence) Solve(ctx context.Context, comm Comm) {
+	for _, solver := range s {
+		if ctx.Err() != nil {
+			return
+		}
+		solver.Solve(ctx, comm)
+	}
+}
+
+// SetIterations passes the budget on to the solvers that take one.
+func (s sequence) SetIterations(n int) {
+	for _, solver := range s {
+		if b, ok := solver.(Budgeted); ok {
+			b.SetIterations(n)
+		}
+	}
+}
+
+// Named attributes the tours the solver sends to name, see WithSolver.
+func Named(name string, s Solver) Solver {
+	return &namedSolver{name, s}
+}
+
+type namedSolver struct {
+	name   string
+	solver Solver
+}
+
+func (n *namedSolver) Solve(ctx context.Context, comm Comm) {
+	n.solver.Solve(ctx, WithSolver(comm, n.name))
+}
+
+// waitForTour polls comm until some solver published a tour or ctx is done,
+// without wait it only looks once
+func waitForTour(ctx context.Context, comm Comm, wait bool) (Solution, bool) {
+	if !wait {
+		s := comm.Current()
+		return s, len(s.Flights) > 0
+	}
+	tick := time.NewTicker(10 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		if s := comm.Current(); len(s.Flights) > 0 {
+			return s, true
+		}
+		select {
+		case <-ctx.Done():
+			return Solution{}, false
+		case <-tick.C:
+		}
+	}
+}
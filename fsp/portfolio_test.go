@@ -0,0 +1,218 @@
+package fsp
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPortfolio(t *testing.T) {
+	in, err := os.Open("../data/1.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	problem, err := ReadInput(in, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	problem.timeLimit = 200 * time.Millisecond
+	p, err := NewPortfolio(problem, 4, []string{"sa", "local"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), problem.timeLimit)
+	defer cancel()
+	c := NewComm(problem)
+	c.Run(ctx, "portfolio", p)
+	if !c.Wait(ctx, time.Second) {
+		t.Fatal("portfolio did not exit")
+	}
+	s := c.Current()
+	if len(Validate(problem, s)) > 0 {
+		t.Fatal("invalid portfolio tour", s.Flights)
+	}
+	if s.TotalCost < 1396 {
+		t.Fatal("portfolio beat the optimum", s.TotalCost)
+	}
+
+	if _, err := NewPortfolio(problem, 2, []string{"sa", "nope"}, 1); err == nil {
+		t.Fatal("unknown solver accepted")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	in, err := os.Open("../data/2.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	problem, err := ReadInput(in, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	problem.timeLimit = time.Hour
+	p, err := NewPortfolio(problem, 4, []string{"greedy", "sa", "local"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solvers := []Solver{NewGreedy(problem), p}
+	for si, s := range solvers {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		c := NewComm(problem)
+		c.Run(ctx, "test", s)
+		if !c.Wait(ctx, time.Second) {
+			t.Fatal(si, "solver did not exit after cancellation")
+		}
+		cancel()
+	}
+}
+
+func TestSingleWorker(t *testing.T) {
+	in, err := os.Open("../data/2.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	problem, err := ReadInput(in, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solve := func(s Solver) Solution {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		c := NewComm(problem)
+		c.Run(ctx, "test", s)
+		c.Wait(ctx, 0)
+		return c.Current()
+	}
+	greedy := solve(NewGreedy(problem))
+	// one worker still has to improve the greedy tour
+	p, err := NewPortfolio(problem, 1, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetIterations(200000)
+	if s := solve(p); len(Validate(problem, s)) > 0 || s.TotalCost >= greedy.TotalCost {
+		t.Fatal("single worker did not improve", greedy.TotalCost, "to", s.TotalCost)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	in, err := os.Open("../data/1.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	problem, err := ReadInput(in, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func() string {
+		p, err := NewPortfolio(problem, 4, []string{"greedy", "sa", "local"}, 42)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.SetIterations(20000)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		c := NewComm(problem)
+		c.Run(ctx, "portfolio", p)
+		c.Wait(ctx, 0)
+		meta := c.Meta()
+		meta.Elapsed = 0
+		var b bytes.Buffer
+		if err := WriteJSON(&b, problem, c.Current(), meta, "EUR"); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+	if a, b := run(), run(); a != b {
+		t.Fatal("replay differs\n", a, "\n", b)
+	}
+
+	seen := make(map[int64]bool)
+	for i := 0; i < 64; i++ {
+		s := DeriveSeed(42, i)
+		if seen[s] {
+			t.Fatal("derived seed repeats", i)
+		}
+		seen[s] = true
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	// every way through Y revisits the area the tour came from
+	revisits, err := ReadInput(strings.NewReader(`4 AAA
+H
+AAA
+X
+BBB BB2
+Y
+CC1 CC2
+Z
+DDD DD2
+AAA BBB 1 10
+BBB CC1 2 10
+CC1 BB2 3 10
+BB2 AAA 4 10
+AAA DDD 1 10
+DDD CC2 2 10
+CC2 DD2 3 10
+DD2 AAA 4 10
+`), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := LowerBound(revisits); !ok {
+		t.Fatal("the bound should not see through this one")
+	}
+	p, err := NewPortfolio(revisits, 3, []string{"greedy", "sa", "tabu"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the only tour costs more than any int32
+	expensive, err := ReadInput(strings.NewReader(`2 AAA
+H
+AAA
+B
+BBB
+AAA BBB 1 2147483647
+BBB AAA 2 5
+`), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		problem *Problem
+		solver  Solver
+		outcome Outcome
+		cost    Money
+	}{
+		{revisits, NewGreedy(revisits), Infeasible, 0},
+		{revisits, NewExact(revisits), Infeasible, 0},
+		{revisits, p, Infeasible, 0},
+		{revisits, NewBeam(revisits, 4), NotFound, 0},
+		{expensive, NewGreedy(expensive), Found, 2147483652},
+		{expensive, NewExact(expensive), Found, 2147483652},
+	}
+	for ti, test := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		c := NewComm(test.problem)
+		c.Run(ctx, "test", test.solver)
+		// the proof stops the portfolio long before the deadline
+		if !c.Wait(ctx, time.Second) {
+			t.Fatal(ti, "solver did not exit")
+		}
+		cancel()
+		if o := c.Meta().Outcome; o != test.outcome {
+			t.Fatal(ti, "outcome", o)
+		}
+		if s := c.Current(); test.outcome == Found && s.TotalCost != test.cost {
+			t.Fatal(ti, "cost", s.TotalCost)
+		}
+	}
+}
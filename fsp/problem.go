@@ -0,0 +1,201 @@
+// Package fsp solves the Flying salesman problem vol 2.0: find the cheapest
+// sequence of flights that starts in the home city, visits every area exactly
+// once, one area per day, and returns to the home area on the last day.
+package fsp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+/* Notes:
+ * - more identical flights with different prices can appear - filter during input reading?
+ *   - we cannot assume any order of input (available testing data are sorted by day or by src, dst, day)
+ * - simulated anealing seems to rock best in last challenge
+ * - ending in the same area, not city
+ * - index of the first day is 1, 0 has special meaning (flight occuring on every day)
+ */
+
+/* TODO:
+ * - search for better solutions for whole time limit
+ */
+
+// MAX_LENGTH is the longest trip the Day and Area types can describe, the
+// indices also hold the day after the last one. Cities are bounded by the
+// codeSpace of three character codes, which fits City.
+const MAX_LENGTH int = math.MaxUint16 - 1
+
+// priceLimit is the highest flight price of a trip of length days, the cost of
+// any tour of such flights fits Money and stays below unreachable
+func priceLimit(length int) uint64 {
+	return (math.MaxUint32 - 1) / uint64(length)
+}
+
+type Day uint16
+type City uint16
+type Area uint16
+type Money uint32
+
+type Flight struct {
+	From      City
+	To        City
+	FromArea  Area
+	ToArea    Area
+	Day       Day
+	Cost      Money
+	Heuristic Money
+	Penalty   float64
+}
+
+func (f *Flight) String() string {
+	return fmt.Sprintf("{[%v/%v]->[%v/%v]d%v:$%v}",
+		f.FromArea, f.From, f.ToArea, f.To, f.Day, f.Cost)
+}
+
+type Solution struct {
+	Flights   []*Flight
+	TotalCost Money
+}
+
+func NewSolution(flights []*Flight) Solution {
+	sort.Sort(byDay(flights))
+	return Solution{flights, cost(flights)}
+}
+
+type LookupA struct {
+	nameToIndex map[string]Area
+	indexToName []string
+}
+
+type LookupC struct {
+	codeToIndex [codeSpace]City // city index + 1, 0 for unknown codes
+	indexToName []string
+}
+
+type AreaDb struct {
+	cityToArea   map[City]Area
+	areaToCities map[Area][]City
+}
+
+type Problem struct {
+	flights []Flight
+	indices FlightIndices
+	//areas []Area
+	areaDb     AreaDb
+	areaLookup LookupA
+	cityLookup LookupC
+	start      City
+	goal       Area
+	length     int
+	timeLimit  time.Duration
+	skipped    int
+	pruned     int
+	census     *census // of the flights before pruning, for Diagnose
+	// input holds every flight line, Validate looks up the flights that
+	// are not in the graph there
+	input *flightArena
+	// lb is computed on first use by bounds
+	lbOnce sync.Once
+	lb     *bounds
+}
+
+// bounds returns the cheap lower bounds of the problem, computing them once
+func (p *Problem) bounds() *bounds {
+	p.lbOnce.Do(func() {
+		p.lb = newBounds(p)
+	})
+	return p.lb
+}
+
+// Length is the number of days of the trip, equal to the number of areas.
+func (p *Problem) Length() int {
+	return p.length
+}
+
+// TimeLimit is the contest time limit for the problem size.
+func (p *Problem) TimeLimit() time.Duration {
+	return p.timeLimit
+}
+
+// Skipped is the number of malformed flight lines dropped in lenient mode.
+func (p *Problem) Skipped() int {
+	return p.skipped
+}
+
+// Pruned is the number of flights dropped because no tour can take them.
+func (p *Problem) Pruned() int {
+	return p.pruned
+}
+
+// CityName returns the three letter code of the city.
+func (p *Problem) CityName(c City) string {
+	return name(p.cityLookup.indexToName, int(c))
+}
+
+// AreaName returns the name of the area.
+func (p *Problem) AreaName(a Area) string {
+	return name(p.areaLookup.indexToName, int(a))
+}
+
+func (p *Problem) flightString(f *Flight) string {
+	return fmt.Sprintf("{[%v/%v]->[%v/%v]d%v:$%v}",
+		p.AreaName(f.FromArea), p.CityName(f.From),
+		p.AreaName(f.ToArea), p.CityName(f.To), f.Day, f.Cost)
+}
+
+func name(arr []string, i int) string {
+	if len(arr) > i {
+		return arr[i]
+	} else {
+		return fmt.Sprintf("%v", i)
+	}
+}
+
+type byCost []*Flight
+
+func (f byCost) Len() int {
+	return len(f)
+}
+func (f byCost) Swap(i, j int) {
+	f[i], f[j] = f[j], f[i]
+}
+func (f byCost) Less(i, j int) bool {
+	return f[i].Cost < f[j].Cost
+}
+
+type byDay []*Flight
+
+func (f byDay) Len() int {
+	return len(f)
+}
+func (f byDay) Swap(i, j int) {
+	f[i], f[j] = f[j], f[i]
+}
+func (f byDay) Less(i, j int) bool {
+	return f[i].Day < f[j].Day
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func order(i, j int) (int, int) {
+	if i < j {
+		return i, j
+	}
+	return j, i
+}
+
+func cost(path []*Flight) Money {
+	var cost Money
+	for _, f := range path {
+		cost += f.Cost
+	}
+	return cost
+}
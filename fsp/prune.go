@@ -0,0 +1,60 @@
+package fsp
+
+/*****************************************************************************/
+/* Reachability pruning                                                      */
+/*****************************************************************************/
+
+// prune removes the flights no tour can take and returns how many there were.
+// A flight is kept when its city can be reached on its day from the start
+// and the home area can be reached from its destination by the end of the
+// trip, using only flights a tour may take at all. The graph is layered by
+// day, so one forward and one backward pass reach the fixed point: every
+// flight on a path into a kept flight can continue through it and is kept
+// as well.
+func (p *Problem) prune() int {
+	g := &p.indices.fromDayTo
+	keep := make([]bool, len(g.flights))
+	// forward: at[c] is whether a tour can be in city c on the current day
+	at := make([]bool, g.cities)
+	next := make([]bool, g.cities)
+	at[p.start] = true
+	for d := 1; d <= p.length; d++ {
+		for c := range next {
+			next[c] = false
+		}
+		for c, ok := range at {
+			if !ok {
+				continue
+			}
+			lo, hi := g.row(City(c), Day(d))
+			for i := lo; i < hi; i++ {
+				if f := &g.flights[i]; p.usable(f) {
+					keep[i], next[f.To] = true, true
+				}
+			}
+		}
+		at, next = next, at
+	}
+	// backward: done[c] is whether the trip can end from city c on the
+	// day after the current one
+	done := at
+	for c := range done {
+		done[c] = p.areaDb.cityToArea[City(c)] == p.goal
+	}
+	before := next
+	for d := p.length; d >= 1; d-- {
+		for c := range before {
+			before[c] = false
+			lo, hi := g.row(City(c), Day(d))
+			for i := lo; i < hi; i++ {
+				if keep[i] = keep[i] && done[g.flights[i].To]; keep[i] {
+					before[c] = true
+				}
+			}
+		}
+		done, before = before, done
+	}
+	pruned := g.compact(keep)
+	p.flights = g.flights
+	return pruned
+}
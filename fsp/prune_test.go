@@ -0,0 +1,54 @@
+package fsp
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPrune(t *testing.T) {
+	input := `3 AAA
+Home
+AAA
+X
+BBB CCC
+Y
+DDD
+AAA BBB 1 10
+BBB DDD 2 10
+DDD AAA 3 10
+AAA CCC 1 5
+CCC DDD 3 5
+DDD BBB 2 7
+BBB DDD 0 20
+`
+	p, err := ReadInput(strings.NewReader(input), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the dead end into CCC, the flights out of cities no tour is in on
+	// their day and the day 3 copy of the day 0 flight
+	if p.Pruned() != 4 || len(p.flights) != 4 {
+		t.Fatal("pruned", p.Pruned(), "kept", len(p.flights))
+	}
+	g := &p.indices.fromDayTo
+	AAA, BBB, CCC, DDD := City(0), City(1), City(2), City(3)
+	if g.get(AAA, 1, CCC) != nil || g.get(BBB, 3, DDD) != nil {
+		t.Fatal("dead-end flight kept")
+	}
+	if f := g.get(BBB, 2, DDD); f == nil || f.Cost != 10 || len(g.departures(BBB, 2)) != 2 {
+		t.Fatal("live flights lost", g.departures(BBB, 2))
+	}
+	s, ok := NewExact(p).Optimum(context.Background())
+	if !ok || s.TotalCost != 30 {
+		t.Fatal("optimum", s.TotalCost, ok)
+	}
+	// a one day trip stays in the home area
+	p, err = ReadInput(strings.NewReader("1 AAA\nHome\nAAA BBB\nAAA BBB 1 10\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lb, ok := LowerBound(p); p.Pruned() != 0 || !ok || lb != 10 {
+		t.Fatal("one day trip pruned", p.Pruned(), "bound", lb, ok)
+	}
+}
@@ -0,0 +1,294 @@
+package fsp
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// segmentLength bounds the segments SA reverses or relocates at random
+const segmentLength = 8
+
+type SA struct {
+	problem  *Problem
+	rng      *rand.Rand
+	schedule schedule
+	budget   time.Duration
+	// cooling names the schedule and heat scales its start temperature when
+	// schedule is not set explicitly
+	cooling string
+	heat    float64
+	// syncEvery iterations the walk restarts from the global best if another
+	// solver found a better tour, 0 never syncs
+	syncEvery int
+	// iterations replaces the time budget when set
+	iterations int
+}
+
+func NewSA(problem *Problem, seed int64) *SA {
+	return &SA{problem: problem, rng: rand.New(rand.NewSource(seed))}
+}
+
+// accept decides whether the walk moves from a tour of cost old to a tour of
+// cost new at temperature t (Metropolis criterion).
+func accept(rng *rand.Rand, old, new Money, t float64) bool {
+	if new <= old {
+		return true
+	}
+	if t <= 0 {
+		return false
+	}
+	delta := float64(new) - float64(old)
+	return rng.Float64() < math.Exp(-delta/t)
+}
+
+// SetCooling selects the cooling schedule by name: geometric (the default),
+// adaptive, lundy-mees or linear.
+func (d *SA) SetCooling(name string) error {
+	if _, err := newSchedule(name, 2, 1); err != nil {
+		return err
+	}
+	d.cooling = name
+	return nil
+}
+
+// SetIterations makes the walk take n steps instead of watching the clock.
+func (d *SA) SetIterations(n int) {
+	d.iterations = n
+}
+
+// Solve waits for another solver to publish the first tour and anneals it.
+func (d *SA) Solve(ctx context.Context, comm Comm) {
+	if _, ok := waitForTour(ctx, comm, d.iterations == 0); ok {
+		d.Run(ctx, comm)
+	}
+}
+
+// Run anneals the current best tour of comm for the time budget, which
+// defaults to the time left until the ctx deadline or the problem time limit.
+func (d *SA) Run(ctx context.Context, comm Comm) {
+	current := comm.Current()
+	flights := current.Flights
+	best := make([]*Flight, len(flights))
+	copy(best, flights)
+	bestCost := current.TotalCost
+	g := d.problem.indices.fromDayTo
+	areadb := d.problem.areaDb
+	if d.schedule == nil {
+		t0, tEnd := initialTemperature(current)
+		if d.heat > 0 {
+			t0 = math.Max(t0*d.heat, tEnd)
+		}
+		// the name was checked by SetCooling
+		d.schedule, _ = newSchedule(d.cooling, t0, tEnd)
+	}
+	if deadline, ok := ctx.Deadline(); ok && d.budget == 0 {
+		d.budget = time.Until(deadline)
+	}
+	if d.budget <= 0 {
+		d.budget = d.problem.timeLimit
+	}
+	start := time.Now()
+	t := d.schedule.temperature(0, false)
+	maxCitySwap, maxAreaSwap := len(flights)-2, len(flights)-1
+	for iter := 1; ; iter++ {
+		progress := float64(time.Since(start)) / float64(d.budget)
+		if d.iterations > 0 {
+			progress = float64(iter) / float64(d.iterations)
+		}
+		if progress > 1 {
+			return
+		}
+		if iter&255 == 0 && ctx.Err() != nil {
+			return
+		}
+		newBest := false
+		//don't swap first and last city
+		if maxCitySwap > 1 {
+			i, j := randomFlightSwap(d.rng, maxCitySwap)
+			ok, newCost := swapFlights(current, g, i, j, false)
+			if ok && accept(d.rng, current.TotalCost, newCost, t) {
+				current.TotalCost = newCost
+				swapFlights(current, g, i, j, true)
+				if bestCost > newCost {
+					bestCost, newBest = newCost, true
+					copy(best, flights)
+				}
+			}
+		}
+		//don't swap first city but can swap last city
+		if maxAreaSwap > 1 {
+			fi, ci := randomAreaSwap(d.rng, maxAreaSwap, flights, areadb)
+			ok, newCost := swapInArea(current, g, fi, ci, false)
+			if ok && accept(d.rng, current.TotalCost, newCost, t) {
+				current.TotalCost = newCost
+				swapInArea(current, g, fi, ci, true)
+				if bestCost > newCost {
+					bestCost, newBest = newCost, true
+					copy(best, flights)
+				}
+			}
+		}
+		//reverse or relocate a short segment
+		if maxCitySwap > 1 {
+			m := randomSegmentMove(d.rng, len(flights), segmentLength)
+			ok, newCost := m.try(current, g, false)
+			if ok && accept(d.rng, current.TotalCost, newCost, t) {
+				current.TotalCost = newCost
+				m.try(current, g, true)
+				if bestCost > newCost {
+					bestCost, newBest = newCost, true
+					copy(best, flights)
+				}
+			}
+		}
+		if newBest {
+			logln(Verbose, "sa new solution", bestCost)
+			comm.Send(Solution{best, bestCost})
+		}
+		if d.syncEvery > 0 && iter%d.syncEvery == 0 {
+			if global := comm.Current(); global.TotalCost < bestCost {
+				copy(flights, global.Flights)
+				copy(best, global.Flights)
+				current.TotalCost, bestCost = global.TotalCost, global.TotalCost
+			}
+		}
+		t = d.schedule.temperature(progress, newBest)
+	}
+}
+
+/*
+0 ---- 1 ---- 2 ---- 4
+A      B      C      A
+a->b   b->c   c->d
+fiPrev fi
+a->X   X->c   c->d
+giPrev gi
+*/
+func swapInArea(s Solution, g Graph, i int, x City, really bool) (bool, Money) {
+	if i == -1 {
+		return false, 0
+	}
+	flights := s.Flights
+	prevI := i - 1
+	fiPrev := flights[prevI]
+	giPrev := g.get(fiPrev.From, fiPrev.Day, x)
+	fi := flights[i]
+	gi := g.get(x, fi.Day, fi.To)
+	if giPrev != nil && gi != nil {
+		oldCost := fiPrev.Cost + fi.Cost
+		newCost := giPrev.Cost + gi.Cost
+		if really {
+			flights[prevI] = giPrev
+			flights[i] = gi
+		}
+		return true, s.TotalCost - oldCost + newCost
+	}
+	return false, 0
+}
+
+/*
+0 ---- 1 ---- 2 ---- 3 ---- 4
+A      B      C      D      A
+a->b   b->c   c->d   d->a
+fiPrev fi     fjPrev fj
+a->d   d->c   c->b   b->a
+giPrev gi     gjPrev gj
+*/
+func swapFlights(s Solution, g Graph, i, j int, really bool) (bool, Money) {
+	if i == -1 || j == -1 {
+		return false, 0
+	}
+	flights := s.Flights
+	prevI := i - 1
+	prevJ := j - 1
+	fiPrev := flights[prevI]
+	fjPrev := flights[prevJ]
+	giPrev := g.get(fiPrev.From, fiPrev.Day, fjPrev.To)
+	gjPrev := g.get(fjPrev.From, fjPrev.Day, fiPrev.To)
+	fi := flights[i]
+	fj := flights[j]
+	gi := g.get(fj.From, fi.Day, fi.To)
+	gj := g.get(fi.From, fj.Day, fj.To)
+	if giPrev != nil && gjPrev != nil && gi != nil && gj != nil {
+		oldCost := fiPrev.Cost + fi.Cost + fjPrev.Cost + fj.Cost
+		newCost := giPrev.Cost + gi.Cost + gjPrev.Cost + gj.Cost
+		if really {
+			flights[prevI] = giPrev
+			flights[i] = gi
+			flights[prevJ] = gjPrev
+			flights[j] = gj
+		}
+		return true, s.TotalCost - oldCost + newCost
+	}
+	return false, 0
+}
+
+/*****************************************************************************/
+/* SA heuristics                                                             */
+/*****************************************************************************/
+
+// bestFlightSwap scans every pair of positions up to max, deltaCache keeps
+// the same result up to date incrementally
+func bestFlightSwap(s Solution, g Graph, max int) (int, int) {
+	bi, bj, best := -1, -1, unreachable
+	maxi := max - 1
+	for i := 1; i <= maxi; i++ {
+		for j := i + 1; j <= max; j++ {
+			ok, newCost := swapFlights(s, g, i, j, false)
+			if ok && best > newCost {
+				best, bi, bj = newCost, i, j
+			}
+		}
+	}
+	return bi, bj
+}
+
+// bestAreaSwap scans every city of the areas up to position max, see
+// deltaCache
+func bestAreaSwap(s Solution, g Graph, max int, flights []*Flight, areadb AreaDb) (int, City) {
+	bfi, bci, best := -1, City(0), unreachable
+	for fi := 1; fi <= max; fi++ {
+		from := flights[fi].From
+		a := areadb.cityToArea[from]
+		area := areadb.areaToCities[a]
+		for _, ci := range area {
+			ok, newCost := swapInArea(s, g, fi, ci, false)
+			if ok && best > newCost {
+				best, bfi, bci = newCost, fi, ci
+			}
+		}
+	}
+	return bfi, bci
+}
+
+//TODO: could use some heuristics instead of random maybe
+func randomFlightSwap(rng *rand.Rand, n int) (int, int) {
+	i := rng.Intn(n)
+	j := rng.Intn(n)
+	for ; j == i; j = rng.Intn(n) {
+	}
+	return order(i+1, j+1)
+}
+
+//TODO: could use some heuristics instead of random maybe
+func randomAreaSwap(rng *rand.Rand, n int, flights []*Flight, areadb AreaDb) (int, City) {
+	fi := rng.Intn(n-1) + 1
+	from := flights[fi].From
+	a := areadb.cityToArea[from]
+	area := areadb.areaToCities[a]
+	if len(area) < 2 {
+		return -1, 0
+	}
+	ci := rng.Intn(len(area))
+	max := 5
+	for ; area[ci] == from && max > 0; ci = rng.Intn(len(area)) {
+		max--
+	}
+	if max == 0 {
+		return -1, 0
+	}
+
+	return fi, City(area[ci])
+}
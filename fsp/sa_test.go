@@ -1,144 +1,48 @@
-package main
+package fsp
 
 import (
-	"bufio"
 	"math"
+	"math/rand"
 	"reflect"
-	"strings"
+	"sort"
 	"testing"
 )
 
-type testcomm struct {
-	solution Solution
-}
-
-func (t *testcomm) send(r Solution) Money {
-	t.solution = r
-	return r.totalCost
-}
-func (t *testcomm) done() {
-}
-func (t *testcomm) current() Solution {
-	return t.solution
-}
-
-func eq(f1, f2 Flight) bool {
-	if f1.From != f2.From {
-		return false
-	}
-	if f1.To != f2.To {
-		return false
-	}
-	if f1.FromArea != f2.FromArea {
-		return false
-	}
-	if f1.ToArea != f2.ToArea {
-		return false
-	}
-	if f1.Day != f2.Day {
-		return false
-	}
-	if f1.Cost != f2.Cost {
-		return false
-	}
-	return true
-}
-
-func TestSolve(t *testing.T) {
-	input := `3 ASD
-Green
-ASD
-Red
-SKT
-Blue
-MXT GDO
-ASD MXT 1 50
-ASD GDO 1 10
-SKT ASD 0 30
-MXT SKT 2 20
-GDO SKT 2 90
-`
-	/*ASD := City(0)
-	SKT := City(1)
-	MXT := City(2)
-	GDO := City(3)
-	G := Area(0)
-	R := Area(1)
-	B := Area(2)
-
-	problem := readInput(bufio.NewScanner(strings.NewReader(input)))
-	flights := []Flight{
-		{ASD, MXT, G, B, Day(1), 50, 0, 0.0},
-		{ASD, GDO, G, B, Day(1), 10, 0, 0.0},
-		{SKT, ASD, R, G, Day(0), 30, 0, 0.0},
-		{SKT, ASD, R, G, Day(1), 30, 0, 0.0},
-		{SKT, ASD, R, G, Day(2), 30, 0, 0.0},
-		{MXT, SKT, B, R, Day(2), 20, 0, 0.0},
-		{GDO, SKT, B, R, Day(2), 90, 0, 0.0},
-	}
-	expected := []*Flight{
-		&Flight{0, 1},
-	}*/
-	readInput(bufio.NewScanner(strings.NewReader(input)))
-	g := Greedy{graph: problem.indices, currentBest: math.MaxInt32}
-	c := &testcomm{}
-	g.Solve(c)
-	printSolution(c.solution)
-	if c.solution.totalCost != 100 {
-		t.Fatalf("sample test cost %v != 100", c.solution.totalCost)
-	}
-}
-
-func TestAreaSolve(t *testing.T) {
-	input := `3 ASD
-Green
-ASD TMP
-Red
-SKT
-Blue
-MXT GDO
-ASD MXT 1 50
-ASD GDO 1 10
-SKT TMP 0 30
-MXT SKT 2 20
-GDO SKT 2 90
-`
-	readInput(bufio.NewScanner(strings.NewReader(input)))
-	g := Greedy{graph: problem.indices, currentBest: math.MaxInt32}
-	c := &testcomm{}
-	g.Solve(c)
-	printSolution(c.solution)
-	if c.solution.totalCost != 100 {
-		t.Fatalf("sample test cost %v != 100", c.solution.totalCost)
-	}
-}
 func mockGraph() Graph {
-	g := emptyGraph()
-	g[1][1][2] = f(1, 2, 1, 5)
-	g[2][2][3] = f(2, 3, 2, 10)
-	g[3][3][4] = f(3, 4, 3, 10)
-	g[4][4][1] = f(4, 1, 4, 10)
+	return graphOf(
+		f(1, 2, 1, 5),
+		f(2, 3, 2, 10),
+		f(3, 4, 3, 10),
+		f(4, 1, 4, 10),
 
-	g[1][1][4] = f(1, 4, 1, 10)
-	g[4][2][3] = f(4, 3, 2, 5)
-	g[3][3][2] = f(3, 2, 3, 5)
-	g[2][4][1] = f(2, 1, 4, 10)
+		f(1, 4, 1, 10),
+		f(4, 3, 2, 5),
+		f(3, 2, 3, 5),
+		f(2, 1, 4, 10),
 
-	g[1][1][7] = &Flight{1, 7, 1, 2, 1, 6, 0, 0.0}
-	g[7][2][3] = &Flight{7, 3, 2, 3, 2, 6, 0, 0.0}
-	return g
+		&Flight{1, 7, 1, 2, 1, 6, 0, 0.0},
+		&Flight{7, 3, 2, 3, 2, 6, 0, 0.0},
+	)
 }
 func emptyGraph() Graph {
-	s := 8
-	g := Graph{}
-	g = make([][][]*Flight, s)
-	for i := 0; i < s; i++ {
-		g[i] = make([][]*Flight, s)
-		for j := 0; j < s; j++ {
-			g[i][j] = make([]*Flight, s)
-		}
-	}
-	return g
+	return graphOf()
+}
+
+// graphOf builds a graph of 8 cities and 6 days out of the flights
+func graphOf(flights ...*Flight) Graph {
+	g := newGraph(8, 6)
+	sort.SliceStable(flights, func(i, j int) bool {
+		return flights[i].Cost < flights[j].Cost
+	})
+	for _, fl := range flights {
+		g.count(fl.From, fl.Day)
+	}
+	g.allocate()
+	for _, fl := range flights {
+		g.add(*fl)
+	}
+	g.finish()
+	return *g
 }
 func f(from City, to City, d Day, c Money) *Flight {
 	return &Flight{from, to, Area(from), Area(to), d, c, 0, 0.0}
@@ -158,6 +62,27 @@ func TestGet(t *testing.T) {
 			to:       2,
 			expected: f(1, 2, 1, 5),
 		},
+		{
+			graph:    mockGraph(),
+			from:     1,
+			day:      1,
+			to:       3,
+			expected: nil,
+		},
+		{
+			graph:    mockGraph(),
+			from:     1,
+			day:      7,
+			to:       2,
+			expected: nil,
+		},
+		{
+			graph:    graphOf(f(1, 2, 1, 9), f(1, 3, 1, 1), f(1, 2, 1, 4), f(1, 0, 1, 2)),
+			from:     1,
+			day:      1,
+			to:       2,
+			expected: f(1, 2, 1, 4),
+		},
 	}
 	for ti, test := range tests {
 		f := test.graph.get(test.from, test.day, test.to)
@@ -165,6 +90,13 @@ func TestGet(t *testing.T) {
 			t.Fatal(ti, "flight mismatch")
 		}
 	}
+
+	g := graphOf(f(1, 2, 1, 9), f(1, 3, 1, 1), f(1, 2, 1, 4), f(2, 0, 1, 2))
+	for i, fl := range g.departures(1, 1) {
+		if cost := []Money{1, 4, 9}[i]; fl.Cost != cost {
+			t.Fatal("departures not sorted by cost", g.departures(1, 1))
+		}
+	}
 }
 func TestSwap(t *testing.T) {
 	tests := []struct {
@@ -305,3 +237,16 @@ func TestSwapArea(t *testing.T) {
 		}
 	}
 }
+
+func TestAccept(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	if !accept(rng, 10, 5, 0) {
+		t.Fatal("improvement rejected")
+	}
+	if accept(rng, 5, 10, 0) {
+		t.Fatal("worse move accepted at zero temperature")
+	}
+	if !accept(rng, 5, 6, math.MaxFloat64) {
+		t.Fatal("worse move rejected at infinite temperature")
+	}
+}
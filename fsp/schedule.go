@@ -0,0 +1,91 @@
+package fsp
+
+import (
+	"fmt"
+	"math"
+)
+
+// schedule maps the elapsed fraction of the time budget (0..1) to the
+// annealing temperature; improved reports whether the last iteration found
+// a new best tour.
+type schedule interface {
+	temperature(progress float64, improved bool) float64
+}
+
+// initialTemperature derives the start and end temperature from a tour, the
+// walk starts by accepting a move worse by an average leg with p = 1/e.
+func initialTemperature(s Solution) (float64, float64) {
+	t0 := 2.0
+	if len(s.Flights) > 0 {
+		t0 = math.Max(t0, float64(s.TotalCost)/float64(len(s.Flights)))
+	}
+	return t0, 1.0
+}
+
+// newSchedule returns the cooling schedule by name, geometric when the name is
+// empty.
+func newSchedule(name string, t0, tEnd float64) (schedule, error) {
+	switch name {
+	case "geometric", "":
+		return &geometric{t0, tEnd}, nil
+	case "linear":
+		return &linear{t0, tEnd}, nil
+	case "lundy-mees":
+		return &lundyMees{t0, (t0 - tEnd) / (t0 * tEnd)}, nil
+	case "adaptive":
+		return &adaptive{geometric: geometric{t0, tEnd}, boost: 1, patience: 1000, reheat: 10}, nil
+	}
+	return nil, fmt.Errorf("unknown cooling schedule %q", name)
+}
+
+// geometric cools exponentially, T = t0 * (tEnd/t0)^progress
+type geometric struct {
+	t0, tEnd float64
+}
+
+func (s *geometric) temperature(progress float64, improved bool) float64 {
+	return s.t0 * math.Pow(s.tEnd/s.t0, progress)
+}
+
+// linear cools at constant rate from t0 to tEnd
+type linear struct {
+	t0, tEnd float64
+}
+
+func (s *linear) temperature(progress float64, improved bool) float64 {
+	return s.t0 - (s.t0-s.tEnd)*progress
+}
+
+// lundyMees is T = t0 / (1 + beta*t0*progress), beta is chosen so that the
+// temperature reaches tEnd at the end of the budget
+type lundyMees struct {
+	t0, beta float64
+}
+
+func (s *lundyMees) temperature(progress float64, improved bool) float64 {
+	return s.t0 / (1 + s.beta*s.t0*progress)
+}
+
+// adaptive cools geometrically but reheats by reheat factor after patience
+// iterations without a new best, the boost then decays back to 1
+type adaptive struct {
+	geometric
+	boost    float64
+	stall    int
+	patience int
+	reheat   float64
+}
+
+func (s *adaptive) temperature(progress float64, improved bool) float64 {
+	if improved {
+		s.stall = 0
+	} else {
+		s.stall++
+	}
+	if s.stall >= s.patience {
+		s.stall = 0
+		s.boost = s.reheat
+	}
+	s.boost = math.Max(1, s.boost*0.999)
+	return s.geometric.temperature(progress, improved) * s.boost
+}
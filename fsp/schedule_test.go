@@ -0,0 +1,51 @@
+package fsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSchedule(t *testing.T) {
+	for _, name := range []string{"geometric", "linear", "lundy-mees", "adaptive"} {
+		s, err := newSchedule(name, 100, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if temp := s.temperature(0, false); math.Abs(temp-100) > 1e-6 {
+			t.Fatal(name, "start temperature", temp)
+		}
+		prev := math.Inf(1)
+		for p := 0.0; p <= 1.0; p += 0.1 {
+			temp := s.temperature(p, true)
+			if temp > prev {
+				t.Fatal(name, "temperature rises at", p)
+			}
+			prev = temp
+		}
+		if temp := s.temperature(1, true); math.Abs(temp-1) > 1e-6 {
+			t.Fatal(name, "end temperature", temp)
+		}
+	}
+}
+
+func TestAdaptiveReheat(t *testing.T) {
+	s, _ := newSchedule("adaptive", 100, 1)
+	base := s.temperature(0.5, true)
+	var temp float64
+	for i := 0; i < 1000; i++ {
+		temp = s.temperature(0.5, false)
+	}
+	if temp <= base {
+		t.Fatal("no reheat after stagnation", temp, base)
+	}
+}
+
+func TestUnknownCooling(t *testing.T) {
+	sa := NewSA(nil, 1)
+	if err := sa.SetCooling("geometirc"); err == nil {
+		t.Fatal("misspelled cooling accepted")
+	}
+	if err := sa.SetCooling("lundy-mees"); err != nil || sa.cooling != "lundy-mees" {
+		t.Fatal("cooling not set", err, sa.cooling)
+	}
+}
@@ -0,0 +1,16 @@
+package fsp
+
+// DeriveSeed returns the seed of the i-th solver under a master seed. The
+// splitmix64 finalizer keeps the streams of neighbouring indices unrelated.
+func DeriveSeed(master int64, i int) int64 {
+	z := uint64(master) + uint64(i+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// Budgeted solvers can run for a number of iterations instead of until the
+// ctx deadline, which makes their result depend on the seed only.
+type Budgeted interface {
+	SetIterations(n int)
+}
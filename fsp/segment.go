@@ -0,0 +1,162 @@
+package fsp
+
+import (
+	"context"
+	"math/rand"
+)
+
+/*****************************************************************************/
+/* Segment moves                                                             */
+/*****************************************************************************/
+
+// Position p of a tour of n flights is the city the tour is in on day p+1,
+// flight p leaves it. Positions 1..n-1 are free to reorder, 0 is the start
+// city and n the city of the home area the tour ends in.
+
+// city returns the city at position p of the tour
+func city(flights []*Flight, p int) City {
+	if p == len(flights) {
+		return flights[p-1].To
+	}
+	return flights[p].From
+}
+
+// reconnect prices the tour with the cities at(lo)..at(hi) at positions
+// lo..hi instead. Prices depend on the day, so flights lo-1..hi are looked
+// up again, the evaluation is linear in the range and stops at the first
+// missing flight, which on sparse inputs usually comes within a few lookups.
+func reconnect(s Solution, g Graph, lo, hi int, at func(p int) City, really bool) (bool, Money) {
+	flights := s.Flights
+	oldCost, newCost := Money(0), Money(0)
+	for p := lo - 1; p <= hi; p++ {
+		f := g.get(at(p), Day(p+1), at(p+1))
+		if f == nil {
+			return false, 0
+		}
+		oldCost += flights[p].Cost
+		newCost += f.Cost
+	}
+	if really {
+		// at reads the old tour, remember the new order before rewriting it
+		cities := make([]City, hi-lo+3)
+		for p := lo - 1; p <= hi+1; p++ {
+			cities[p-lo+1] = at(p)
+		}
+		for p := lo - 1; p <= hi; p++ {
+			flights[p] = g.get(cities[p-lo+1], Day(p+1), cities[p-lo+2])
+		}
+	}
+	return true, s.TotalCost - oldCost + newCost
+}
+
+/*
+0 ---- 1 ---- 2 ---- 3 ---- 4 ---- 5
+A      B      C      D      E      A
+i             j
+A      D      C      B      E      A
+*/
+// reverseSegment reverses the order of positions i..j, 1 <= i < j < n
+func reverseSegment(s Solution, g Graph, i, j int, really bool) (bool, Money) {
+	if i < 1 || j <= i || j >= len(s.Flights) {
+		return false, 0
+	}
+	flights := s.Flights
+	return reconnect(s, g, i, j, func(p int) City {
+		if p >= i && p <= j {
+			return city(flights, i+j-p)
+		}
+		return city(flights, p)
+	}, really)
+}
+
+/*
+0 ---- 1 ---- 2 ---- 3 ---- 4 ---- 5
+A      B      C      D      E      A
+i      j             k
+A      C      D      B      E      A
+*/
+// exchangeSegments swaps the neighbouring segments at positions i..j-1 and
+// j..k-1, the 3-opt reconnection that keeps both segments in order,
+// 1 <= i < j < k <= n
+func exchangeSegments(s Solution, g Graph, i, j, k int, really bool) (bool, Money) {
+	if i < 1 || j <= i || k <= j || k > len(s.Flights) {
+		return false, 0
+	}
+	flights := s.Flights
+	m := k - j
+	return reconnect(s, g, i, k-1, func(p int) City {
+		switch {
+		case p < i || p >= k:
+			return city(flights, p)
+		case p < i+m:
+			return city(flights, j+p-i)
+		}
+		return city(flights, p-m)
+	}, really)
+}
+
+// relocateBlock moves the k positions starting at i so that they start at
+// position j instead, or-opt
+func relocateBlock(s Solution, g Graph, i, k, j int, really bool) (bool, Money) {
+	switch {
+	case j > i:
+		return exchangeSegments(s, g, i, i+k, j+k, really)
+	case j < i:
+		return exchangeSegments(s, g, j, i, i+k, really)
+	}
+	return false, 0
+}
+
+// segmentMove is a reversal of positions i..j or a relocation of the k
+// positions at i to j
+type segmentMove struct {
+	reverse bool
+	i, k, j int
+}
+
+func (m segmentMove) try(s Solution, g Graph, really bool) (bool, Money) {
+	if m.reverse {
+		return reverseSegment(s, g, m.i, m.j, really)
+	}
+	return relocateBlock(s, g, m.i, m.k, m.j, really)
+}
+
+// randomSegmentMove picks a reversal or relocation of up to max positions
+func randomSegmentMove(rng *rand.Rand, n, max int) segmentMove {
+	if rng.Intn(2) == 0 {
+		i := rng.Intn(n-2) + 1
+		j := min(i+1+rng.Intn(max-1), n-1)
+		return segmentMove{reverse: true, i: i, j: j}
+	}
+	k := min(rng.Intn(max)+1, n-2)
+	return segmentMove{i: rng.Intn(n-k) + 1, k: k, j: rng.Intn(n-k) + 1}
+}
+
+// bestSegmentMove returns the cheapest reversal of up to max positions or
+// relocation of up to 3 positions and the number of moves it tried, false
+// when none is feasible. It stops early with the best move so far when ctx is
+// done.
+func bestSegmentMove(ctx context.Context, s Solution, g Graph, max int) (segmentMove, int, bool) {
+	n := len(s.Flights)
+	var bm segmentMove
+	best, found, tried := Money(0), false, 0
+	consider := func(m segmentMove) {
+		tried++
+		if ok, c := m.try(s, g, false); ok && (!found || c < best) {
+			bm, best, found = m, c, true
+		}
+	}
+	for i := 1; i < n-1 && ctx.Err() == nil; i++ {
+		for j := i + 1; j < n && j-i < max; j++ {
+			consider(segmentMove{reverse: true, i: i, j: j})
+		}
+		for k := 1; k <= 3 && i+k <= n; k++ {
+			for j := 1; j+k <= n; j++ {
+				if j != i {
+					consider(segmentMove{i: i, k: k, j: j})
+				}
+			}
+		}
+	}
+	return bm, tried, found
+}
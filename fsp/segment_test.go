@@ -0,0 +1,152 @@
+package fsp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// segmentGraph has the tour 1 2 3 4 5 1 and the flights of the segment
+// moves on it, some pairs also fly on the wrong day for cheap
+func segmentGraph() Graph {
+	return graphOf(
+		f(1, 2, 1, 10),
+		f(2, 3, 2, 10),
+		f(3, 4, 3, 10),
+		f(4, 5, 4, 10),
+		f(5, 1, 5, 10),
+
+		f(1, 4, 1, 5),
+		f(4, 3, 2, 5),
+		f(3, 2, 3, 5),
+		f(2, 5, 4, 5),
+		f(3, 2, 2, 1),
+
+		f(1, 3, 1, 7),
+		f(3, 4, 2, 7),
+		f(4, 2, 3, 7),
+
+		f(4, 5, 2, 3),
+		f(5, 2, 3, 3),
+		f(2, 3, 4, 3),
+		f(3, 1, 5, 3),
+	)
+}
+
+func tour() []*Flight {
+	return []*Flight{
+		f(1, 2, 1, 10),
+		f(2, 3, 2, 10),
+		f(3, 4, 3, 10),
+		f(4, 5, 4, 10),
+		f(5, 1, 5, 10),
+	}
+}
+
+func TestSegmentMoves(t *testing.T) {
+	tests := []struct {
+		move     segmentMove
+		graph    Graph
+		expected []*Flight
+		cost     Money
+		ok       bool
+	}{
+		{
+			move:  segmentMove{reverse: true, i: 1, j: 3},
+			graph: segmentGraph(),
+			expected: []*Flight{
+				f(1, 4, 1, 5),
+				f(4, 3, 2, 5),
+				f(3, 2, 3, 5),
+				f(2, 5, 4, 5),
+				f(5, 1, 5, 10),
+			},
+			cost: 30,
+			ok:   true,
+		},
+		{
+			// relocate 2 behind 3 4
+			move:  segmentMove{i: 1, k: 1, j: 3},
+			graph: segmentGraph(),
+			expected: []*Flight{
+				f(1, 3, 1, 7),
+				f(3, 4, 2, 7),
+				f(4, 2, 3, 7),
+				f(2, 5, 4, 5),
+				f(5, 1, 5, 10),
+			},
+			cost: 36,
+			ok:   true,
+		},
+		{
+			// relocate 4 5 in front of 2 3
+			move:  segmentMove{i: 3, k: 2, j: 1},
+			graph: segmentGraph(),
+			expected: []*Flight{
+				f(1, 4, 1, 5),
+				f(4, 5, 2, 3),
+				f(5, 2, 3, 3),
+				f(2, 3, 4, 3),
+				f(3, 1, 5, 3),
+			},
+			cost: 17,
+			ok:   true,
+		},
+		{
+			// 3 2 only exists on day 2 here
+			move:     segmentMove{reverse: true, i: 2, j: 3},
+			graph:    segmentGraph(),
+			expected: tour(),
+			ok:       false,
+		},
+		{
+			move:     segmentMove{reverse: true, i: 1, j: 3},
+			graph:    emptyGraph(),
+			expected: tour(),
+			ok:       false,
+		},
+		{
+			move:     segmentMove{reverse: true, i: 2, j: 5},
+			graph:    segmentGraph(),
+			expected: tour(),
+			ok:       false,
+		},
+		{
+			move:     segmentMove{i: 2, k: 1, j: 2},
+			graph:    segmentGraph(),
+			expected: tour(),
+			ok:       false,
+		},
+	}
+	for ti, test := range tests {
+		flights := tour()
+		s := Solution{flights, cost(flights)}
+		ok, newCost := test.move.try(s, test.graph, false)
+		if ok != test.ok {
+			t.Fatal(ti, "ok mismatch")
+		}
+		if ok && newCost != test.cost {
+			t.Fatal(ti, "money mismatch", newCost)
+		}
+		test.move.try(s, test.graph, true)
+		if !reflect.DeepEqual(flights, test.expected) {
+			t.Fatal(ti, "flight mismatch", flights)
+		}
+		if cost(flights) != test.cost && ok {
+			t.Fatal(ti, "applied money mismatch", cost(flights))
+		}
+	}
+}
+
+func TestBestSegmentMove(t *testing.T) {
+	flights := tour()
+	s := Solution{flights, cost(flights)}
+	m, _, ok := bestSegmentMove(context.Background(), s, segmentGraph(), len(flights))
+	if !ok {
+		t.Fatal("no segment move found")
+	}
+	// 1 4 5 2 3 1, moving either pair of the tour gets there
+	if _, newCost := m.try(s, segmentGraph(), false); newCost != 17 {
+		t.Fatal("best segment move", m, newCost)
+	}
+}
@@ -0,0 +1,38 @@
+package fsp
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+// maxRestarts is how many times a panicking solver is restarted before it
+// is retired
+const maxRestarts = 3
+
+// supervise runs the solver until it returns. A panic is logged with its
+// stack and the solver restarted from the tours in comm, which outlive it,
+// the maxRestarts+1st panic retires it.
+func supervise(ctx context.Context, comm Comm, name string, s Solver) {
+	for panics := 1; solveSafely(ctx, comm, name, s); panics++ {
+		if panics > maxRestarts {
+			logln(Normal, "solver", name, "retired after", panics, "panics")
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		logln(Normal, "restarting solver", name)
+	}
+}
+
+// solveSafely runs the solver once and reports whether it panicked
+func solveSafely(ctx context.Context, comm Comm, name string, s Solver) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			logf(Normal, "solver %v panicked: %v\n%s", name, r, debug.Stack())
+			panicked = true
+		}
+	}()
+	s.Solve(ctx, comm)
+	return false
+}
@@ -0,0 +1,40 @@
+package fsp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// faulty sends a tour with a made up cost and panics
+type faulty struct {
+	calls int
+}
+
+func (f *faulty) Solve(ctx context.Context, comm Comm) {
+	f.calls++
+	s := comm.Current()
+	s.TotalCost = 1
+	comm.Send(s)
+	panic("faulty")
+}
+
+func TestSupervise(t *testing.T) {
+	defer func(level Verbosity) { Level = level }(Level)
+	Level = Quiet
+	problem, _ := solvedSample(t)
+	f := &faulty{}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	c := NewComm(problem)
+	c.Run(ctx, "test", Sequence(NewExact(problem), f))
+	if !c.Wait(ctx, time.Second) {
+		t.Fatal("faulty solver was not retired")
+	}
+	if s := c.Current(); s.TotalCost != 100 || len(Validate(problem, s)) > 0 {
+		t.Fatal("lost the best tour", s.TotalCost)
+	}
+	if meta := c.Meta(); f.calls != maxRestarts+1 || meta.Rejected != f.calls || meta.Solver != "test" {
+		t.Fatal("calls", f.calls, "meta", meta)
+	}
+}
@@ -0,0 +1,154 @@
+package fsp
+
+import (
+	"context"
+	"math/rand"
+)
+
+/*****************************************************************************/
+/* Tabu search                                                               */
+/*****************************************************************************/
+
+// Tabu walks from the current best tour by always applying the cheapest
+// swapFlights or swapInArea move, improving or not. Moving an area away from
+// a position makes putting it back there tabu for tenure moves, changing the
+// city of a position makes further changes of it tabu. A tabu move is taken
+// only when it beats the best tour of all solvers (aspiration).
+type Tabu struct {
+	problem *Problem
+	rng     *rand.Rand
+	// tenure is how many moves an attribute stays tabu, a few more are
+	// added at random to avoid cycles, 0 derives it from the tour length
+	tenure int
+	// stall is how many moves without a new best the walk makes before it
+	// returns to the best tour, 0 derives it from the tour length
+	stall int
+	// iterations is the number of moves to make, 0 runs until ctx is done
+	iterations int
+	// syncEvery moves the walk restarts from the global best if another
+	// solver found a better tour, 0 never syncs
+	syncEvery int
+}
+
+func NewTabu(problem *Problem, seed int64) *Tabu {
+	return &Tabu{problem: problem, rng: rand.New(rand.NewSource(seed))}
+}
+
+// SetIterations limits the walk to n moves.
+func (t *Tabu) SetIterations(n int) {
+	t.iterations = n
+}
+
+func (t *Tabu) Solve(ctx context.Context, comm Comm) {
+	best, ok := waitForTour(ctx, comm, t.iterations == 0)
+	if !ok {
+		return
+	}
+	n := len(best.Flights)
+	if n < 3 {
+		return
+	}
+	current := Solution{make([]*Flight, n), best.TotalCost}
+	copy(current.Flights, best.Flights)
+	g := t.problem.indices.fromDayTo
+	deltas := newDeltaCache(&current, g, t.problem.areaDb)
+	tenure := t.tenure
+	if tenure <= 0 {
+		// the swap neighbourhood is sparse, long tenures push the walk
+		// far uphill
+		tenure = 5 + n/50
+	}
+	// until[p*areas+a] is the first move at which area a may return to
+	// position p
+	areas := len(t.problem.areaLookup.indexToName)
+	until := make([]int, n*areas)
+	tabu := func(it, p int, a Area) bool {
+		return until[p*areas+int(a)] > it
+	}
+	forbid := func(it, p int, a Area) {
+		until[p*areas+int(a)] = it + tenure + t.rng.Intn(tenure/2+1)
+	}
+
+	stall := t.stall
+	if stall <= 0 {
+		stall = 3 * n
+	}
+	lastBest := 0
+	// record is the cost of the global best as of the last send or sync
+	record := best.TotalCost
+	for it := 0; t.iterations == 0 || it < t.iterations; it++ {
+		if ctx.Err() != nil {
+			return
+		}
+		deltas.update()
+		flights := current.Flights
+		// aspiration: a tabu move has to beat the best tour of all solvers
+		aspire := int64(record) - int64(current.TotalCost)
+		bi, bj, bfi, bx, bd := -1, -1, -1, City(0), int64(infeasible)
+		blocked := false
+		for i := 1; i < n-2; i++ {
+			row := deltas.swap[i*n : (i+1)*n]
+			for j := i + 1; j <= n-2; j++ {
+				d := row[j]
+				if d >= bd {
+					continue
+				}
+				if d >= aspire && (tabu(it, i, flights[j].FromArea) || tabu(it, j, flights[i].FromArea)) {
+					blocked = true
+					continue
+				}
+				bi, bj, bfi, bd = i, j, -1, d
+			}
+		}
+		for fi := 1; fi <= n-1; fi++ {
+			for k, d := range deltas.area[fi] {
+				x := deltas.cities[fi][k]
+				if d >= bd || x == flights[fi].From {
+					continue
+				}
+				if d >= aspire && tabu(it, fi, flights[fi].FromArea) {
+					blocked = true
+					continue
+				}
+				bfi, bx, bd = fi, x, d
+			}
+		}
+		switch {
+		case bfi >= 0:
+			forbid(it, bfi, flights[bfi].FromArea)
+			_, current.TotalCost = swapInArea(current, g, bfi, bx, true)
+		case bi >= 0:
+			forbid(it, bi, flights[bi].FromArea)
+			forbid(it, bj, flights[bj].FromArea)
+			_, current.TotalCost = swapFlights(current, g, bi, bj, true)
+		case blocked:
+			// wait for the tabu moves to expire
+			continue
+		default:
+			// there is no feasible move at all
+			return
+		}
+		if current.TotalCost < best.TotalCost {
+			copy(best.Flights, current.Flights)
+			best.TotalCost = current.TotalCost
+			logln(Verbose, "tabu new solution", best.TotalCost)
+			record = comm.Send(best)
+			lastBest = it
+		} else if it-lastBest > stall {
+			// the walk drifted off, go back to the best tour
+			copy(current.Flights, best.Flights)
+			current.TotalCost, lastBest = best.TotalCost, it
+		}
+		if t.syncEvery > 0 && it%t.syncEvery == 0 {
+			global := comm.Current()
+			if global.TotalCost < record {
+				record = global.TotalCost
+			}
+			if global.TotalCost < best.TotalCost {
+				best = global
+				copy(current.Flights, best.Flights)
+				current.TotalCost = best.TotalCost
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package fsp
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestTabu(t *testing.T) {
+	in, err := os.Open("../data/1.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	problem, err := ReadInput(in, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// start from the first tour greedy finds
+	g := NewGreedy(problem)
+	g.endOnFirst = true
+	c := &testcomm{}
+	g.search(context.Background(), c)
+	first := c.solution.TotalCost
+
+	tabu := NewTabu(problem, 1)
+	tabu.SetIterations(2000)
+	tabu.Solve(context.Background(), c)
+	s := c.solution
+	if vs := Validate(problem, s); len(vs) > 0 {
+		t.Fatal("invalid tabu tour", vs)
+	}
+	if s.TotalCost >= first || s.TotalCost < 1396 {
+		t.Fatal("tabu tour", s.TotalCost, "from", first)
+	}
+}
@@ -0,0 +1,124 @@
+package fsp
+
+import "fmt"
+
+// ViolationKind classifies why a solution is not a feasible tour.
+type ViolationKind int
+
+const (
+	BrokenChain   ViolationKind = iota // flight does not leave the city the previous one landed in
+	WrongDay                           // flight is not on the day of its position in the tour
+	Revisit                            // flight lands in an area visited before
+	MissingArea                        // area never visited
+	WrongStart                         // first flight does not leave the start city
+	WrongEnd                           // last flight does not land in the home area on the last day
+	PhantomFlight                      // flight does not exist in the input
+	CostMismatch                       // total cost is not the sum of the flight prices
+	WrongPrice                         // flight exists in the input at another price
+)
+
+var violationNames = []string{"broken chain", "wrong day", "revisit",
+	"missing area", "wrong start", "wrong end", "phantom flight", "cost mismatch",
+	"wrong price"}
+
+func (k ViolationKind) String() string {
+	if int(k) < len(violationNames) {
+		return violationNames[k]
+	}
+	return fmt.Sprintf("violation %d", int(k))
+}
+
+// Violation is one reason why a solution is infeasible, Leg is the index of
+// the offending flight in Solution.Flights or -1 when it concerns the tour
+// as a whole.
+type Violation struct {
+	Kind ViolationKind
+	Leg  int
+	Msg  string
+}
+
+func (v Violation) String() string {
+	if v.Leg < 0 {
+		return fmt.Sprintf("%v: %v", v.Kind, v.Msg)
+	}
+	return fmt.Sprintf("%v: leg %d: %v", v.Kind, v.Leg+1, v.Msg)
+}
+
+// Validate checks that the solution is a feasible tour of the problem and
+// returns every violation found, none for a feasible tour.
+func Validate(p *Problem, s Solution) []Violation {
+	var vs []Violation
+	add := func(kind ViolationKind, leg int, format string, args ...interface{}) {
+		vs = append(vs, Violation{kind, leg, fmt.Sprintf(format, args...)})
+	}
+	if len(s.Flights) == 0 {
+		add(MissingArea, -1, "empty tour")
+		return vs
+	}
+	if f := s.Flights[0]; f.From != p.start {
+		add(WrongStart, 0, "%v does not leave %v", p.flightString(f), p.CityName(p.start))
+	}
+	visited := make([]bool, len(p.areaLookup.indexToName))
+	total := Money(0)
+	last := len(s.Flights) - 1
+	for i, f := range s.Flights {
+		total += f.Cost
+		if int(f.Day) != i+1 {
+			add(WrongDay, i, "%v is not on day %d", p.flightString(f), i+1)
+		}
+		if i > 0 && s.Flights[i-1].To != f.From {
+			add(BrokenChain, i, "%v does not follow %v", p.flightString(f), p.flightString(s.Flights[i-1]))
+		}
+		if price, ok := p.InputPrice(f); !ok {
+			add(PhantomFlight, i, "%v is not in the input", p.flightString(f))
+		} else if price != f.Cost {
+			add(WrongPrice, i, "%v costs %v in the input", p.flightString(f), price)
+		}
+		if int(f.ToArea) >= len(visited) {
+			continue
+		}
+		if visited[f.ToArea] || (f.ToArea == p.goal && i != last) {
+			add(Revisit, i, "%v revisits area %v", p.flightString(f), p.AreaName(f.ToArea))
+		}
+		visited[f.ToArea] = true
+	}
+	if f := s.Flights[last]; f.ToArea != p.goal || int(f.Day) != p.length {
+		add(WrongEnd, last, "%v does not reach %v on day %d", p.flightString(f), p.AreaName(p.goal), p.length)
+	}
+	for a, seen := range visited {
+		if !seen {
+			add(MissingArea, -1, "area %v is never visited", p.AreaName(Area(a)))
+		}
+	}
+	if total != s.TotalCost {
+		add(CostMismatch, -1, "total %v != %v sum of the prices", s.TotalCost, total)
+	}
+	return vs
+}
+
+// exists reports whether the input has the flight with this price
+func (p *Problem) exists(f *Flight) bool {
+	if int(f.From) >= len(p.cityLookup.indexToName) || int(f.To) >= len(p.cityLookup.indexToName) {
+		return false
+	}
+	if f.FromArea != p.areaDb.cityToArea[f.From] || f.ToArea != p.areaDb.cityToArea[f.To] {
+		return false
+	}
+	for _, g := range p.indices.fromDayTo.departures(f.From, f.Day) {
+		if g.To == f.To && g.Cost == f.Cost {
+			return true
+		}
+	}
+	// the flight may have been filtered or pruned as no tour can take it
+	return p.input.has(f.From, f.To, f.Day, f.Cost)
+}
+
+// InputPrice returns the price of the flight in the input, its own when the
+// input has it at that price and the cheapest of the same route and day
+// otherwise. ok is false when the input has no such flight at all.
+func (p *Problem) InputPrice(f *Flight) (price Money, ok bool) {
+	if p.exists(f) {
+		return f.Cost, true
+	}
+	return p.input.cheapest(f.From, f.To, f.Day)
+}
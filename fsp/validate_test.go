@@ -0,0 +1,137 @@
+package fsp
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	in, err := os.Open("../data/0.in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	problem, err := ReadInput(in, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	city := func(code string) City {
+		key, _ := codeKey([]byte(code))
+		return problem.cityLookup.codeToIndex[key] - 1
+	}
+	leg := func(from, to string, day Day) *Flight {
+		return problem.indices.fromDayTo.get(city(from), day, city(to))
+	}
+	// fake is a flight that is not in the input at that price
+	fake := func(from, to string, day Day, cost Money) *Flight {
+		f, t := city(from), city(to)
+		return &Flight{f, t, problem.areaDb.cityToArea[f], problem.areaDb.cityToArea[t], day, cost, 0, 0.0}
+	}
+	tests := []struct {
+		flights    []*Flight
+		cost       Money
+		violations []ViolationKind
+	}{
+		{
+			flights: []*Flight{leg("ASD", "MXT", 1), leg("MXT", "SKT", 2), leg("SKT", "ASD", 3)},
+			cost:    100,
+		},
+		{
+			flights:    []*Flight{leg("ASD", "MXT", 1), leg("MXT", "SKT", 2), leg("SKT", "ASD", 3)},
+			cost:       99,
+			violations: []ViolationKind{CostMismatch},
+		},
+		{
+			flights:    []*Flight{leg("ASD", "MXT", 1), leg("GDO", "SKT", 2), leg("SKT", "ASD", 3)},
+			cost:       170,
+			violations: []ViolationKind{BrokenChain},
+		},
+		{
+			flights:    []*Flight{leg("ASD", "MXT", 1), leg("SKT", "ASD", 3)},
+			cost:       80,
+			violations: []ViolationKind{WrongDay, BrokenChain, MissingArea},
+		},
+		{
+			flights:    []*Flight{leg("ASD", "MXT", 1), fake("MXT", "SKT", 2, 5), leg("SKT", "ASD", 3)},
+			cost:       85,
+			violations: []ViolationKind{WrongPrice},
+		},
+		{
+			flights:    []*Flight{fake("SKT", "MXT", 1, 1), leg("MXT", "SKT", 2), leg("SKT", "ASD", 3)},
+			cost:       51,
+			violations: []ViolationKind{WrongStart, PhantomFlight},
+		},
+		{
+			flights:    []*Flight{leg("ASD", "MXT", 1), leg("MXT", "SKT", 2)},
+			cost:       70,
+			violations: []ViolationKind{WrongEnd, MissingArea},
+		},
+		{
+			flights:    []*Flight{leg("ASD", "GDO", 1), fake("GDO", "MXT", 2, 1), fake("MXT", "ASD", 3, 1)},
+			cost:       12,
+			violations: []ViolationKind{PhantomFlight, Revisit, PhantomFlight, MissingArea},
+		},
+		{
+			violations: []ViolationKind{MissingArea},
+		},
+	}
+	for ti, test := range tests {
+		var kinds []ViolationKind
+		for _, v := range Validate(problem, Solution{test.flights, test.cost}) {
+			kinds = append(kinds, v.Kind)
+		}
+		if !reflect.DeepEqual(kinds, test.violations) {
+			t.Fatal(ti, "violations", kinds, "!=", test.violations)
+		}
+	}
+}
+
+// TestValidateUnusable checks that flights of the input no tour can take are
+// not reported as phantoms, AAA DDD is pruned as DDD is a dead end and BBB AAA
+// on day 2 is dropped while parsing
+func TestValidateUnusable(t *testing.T) {
+	problem, err := ReadInput(strings.NewReader(`3 AAA
+H
+AAA
+B
+BBB
+C
+CCC DDD
+AAA BBB 1 10
+BBB CCC 2 10
+CCC AAA 3 10
+AAA DDD 1 5
+BBB AAA 2 4
+`), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		solution   string
+		violations []ViolationKind
+	}{
+		{
+			solution:   "22\nAAA DDD 1 5\nDDD CCC 2 7\nCCC AAA 3 10\n",
+			violations: []ViolationKind{PhantomFlight, Revisit, MissingArea},
+		},
+		{
+			solution:   "14\nAAA BBB 1 10\nBBB AAA 2 4\n",
+			violations: []ViolationKind{WrongEnd, MissingArea},
+		},
+	}
+	for ti, test := range tests {
+		s, err := ReadSolution(strings.NewReader(test.solution), problem)
+		if err != nil {
+			t.Fatal(ti, err)
+		}
+		var kinds []ViolationKind
+		for _, v := range Validate(problem, s) {
+			kinds = append(kinds, v.Kind)
+		}
+		if !reflect.DeepEqual(kinds, test.violations) {
+			t.Fatal(ti, "violations", kinds, "!=", test.violations)
+		}
+	}
+}
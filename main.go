@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/wozniakjan/fsp2/fsp"
+)
+
+// grace is how long solvers have to exit after the deadline
+const grace = 20 * time.Millisecond
+
+// exit statuses of a run without a tour or cut short, 1 is an error and 2
+// bad usage
+const (
+	exitInfeasible = 3 // there is no tour at all
+	exitNotFound   = 4 // no tour was found in time
+	// a run stopped by a signal exits with exitSignaled plus its number, as
+	// shells report processes killed by one
+	exitSignaled = 128
+)
+
+var (
+	input      = flag.String("input", "", "problem file, stdin when empty")
+	output     = flag.String("output", "", "solution file, stdout when empty")
+	format     = flag.String("format", fsp.FormatText, "output format: text, json or csv")
+	currency   = flag.String("currency", "EUR", "currency of the prices, reported in json and csv output")
+	timeLimit  = flag.Duration("time-limit", 0, "time to search for, 0 uses the contest limit for the problem size")
+	seed       = flag.Int64("seed", 0, "seed of the random number generators, 0 picks one from the clock")
+	solver     = flag.String("solver", "auto", "solver: greedy, beam, sa, tabu, exact, portfolio or auto (exact when small enough, portfolio otherwise)")
+	cooling    = flag.String("cooling", "geometric", "cooling schedule of the sa solver: geometric, adaptive, lundy-mees or linear")
+	beamWidth  = flag.Int("beam-width", fsp.DefaultBeamWidth, "partial tours kept per day by the beam solver")
+	iterations = flag.Int("iterations", 0, "replay mode: run every worker for this many iterations instead of the time limit, same seed and input give the same output")
+	workers    = flag.Int("workers", runtime.GOMAXPROCS(0), "number of portfolio workers")
+	mix        = flag.String("mix", strings.Join(fsp.DefaultMix, ","), "comma separated portfolio worker kinds: greedy, beam, sa, tabu, local")
+	lenient    = flag.Bool("lenient", false, "skip malformed flight lines instead of failing")
+	quiet      = flag.Bool("quiet", false, "print nothing but the solution")
+	verbose    = flag.Bool("verbose", false, "report every improvement found by the solvers")
+	checkpoint = flag.String("checkpoint", "", "file the best tour is written to whenever it improves, for long runs")
+	ckEvery    = flag.Duration("checkpoint-every", 10*time.Second, "minimum time between two checkpoint writes")
+	resume     = flag.Bool("resume", false, "start from the tour in the -checkpoint file instead of building one, when the file exists")
+)
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// loadCheckpoint returns the tour of the checkpoint file with -resume, none
+// when the file does not exist yet
+func loadCheckpoint(problem *fsp.Problem) (fsp.Solution, error) {
+	if !*resume {
+		return fsp.Solution{}, nil
+	}
+	if *checkpoint == "" {
+		return fsp.Solution{}, fmt.Errorf("-resume needs a -checkpoint file")
+	}
+	f, err := os.Open(*checkpoint)
+	if os.IsNotExist(err) {
+		if !*quiet {
+			fmt.Fprintln(os.Stderr, "no checkpoint to resume from, starting afresh")
+		}
+		return fsp.Solution{}, nil
+	}
+	if err != nil {
+		return fsp.Solution{}, err
+	}
+	defer f.Close()
+	s, err := fsp.ReadSolution(f, problem)
+	if err != nil {
+		return fsp.Solution{}, fmt.Errorf("checkpoint %v: %v", *checkpoint, err)
+	}
+	if vs := fsp.Validate(problem, s); len(vs) > 0 {
+		return fsp.Solution{}, fmt.Errorf("checkpoint %v does not fit the problem: %v", *checkpoint, vs[0])
+	}
+	if !*quiet {
+		fmt.Fprintln(os.Stderr, "resuming from", *checkpoint, "at", s.TotalCost)
+	}
+	return s, nil
+}
+
+// newSolver builds the solver selected on the command line, resumed tells
+// that the comm already holds a tour to improve
+func newSolver(problem *fsp.Problem, name string, seed int64, resumed bool) (fsp.Solver, string, error) {
+	if name == "auto" {
+		name = "portfolio"
+		if fsp.CanSolveExactly(problem) {
+			name = "exact"
+		}
+	}
+	// the improvement heuristics start from a greedy tour
+	improve := func(s fsp.Solver) fsp.Solver {
+		if resumed {
+			return s
+		}
+		return fsp.Sequence(fsp.Named("greedy", fsp.NewGreedy(problem)), s)
+	}
+	switch name {
+	case "greedy":
+		return fsp.NewGreedy(problem), name, nil
+	case "sa":
+		sa := fsp.NewSA(problem, seed)
+		if err := sa.SetCooling(*cooling); err != nil {
+			return nil, name, err
+		}
+		return improve(sa), name, nil
+	case "beam":
+		return fsp.NewBeam(problem, *beamWidth), name, nil
+	case "tabu":
+		return improve(fsp.NewTabu(problem, seed)), name, nil
+	case "exact":
+		if !fsp.CanSolveExactly(problem) {
+			return nil, name, fmt.Errorf("problem of %v areas is too large for the exact solver", problem.Length())
+		}
+		return fsp.NewExact(problem), name, nil
+	case "portfolio":
+		p, err := fsp.NewPortfolio(problem, *workers, strings.Split(*mix, ","), seed)
+		return p, name, err
+	}
+	return nil, name, fmt.Errorf("unknown solver %q", name)
+}
+
+func main() {
+	start_time := time.Now()
+	//defer profile.Start(profile.MemProfile).Stop()
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		check(os.Args[2:])
+		return
+	}
+	flag.Parse()
+	if *format != fsp.FormatText && *format != fsp.FormatJSON && *format != fsp.FormatCSV {
+		fmt.Fprintln(os.Stderr, "unknown output format", *format)
+		os.Exit(2)
+	}
+	switch {
+	case *quiet:
+		fsp.Level = fsp.Quiet
+	case *verbose:
+		fsp.Level = fsp.Verbose
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	in := os.Stdin
+	if *input != "" {
+		f, err := os.Open(*input)
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		in = f
+	}
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	problem, err := fsp.ReadInput(in, *lenient)
+	if err != nil {
+		fail(err)
+	}
+	if problem.Skipped() > 0 && !*quiet {
+		fmt.Fprintln(os.Stderr, "skipped", problem.Skipped(), "malformed flight lines")
+	}
+	if problem.Pruned() > 0 && *verbose {
+		fmt.Fprintln(os.Stderr, "pruned", problem.Pruned(), "dead-end flights")
+	}
+	resumed, err := loadCheckpoint(problem)
+	if err != nil {
+		fail(err)
+	}
+	g, name, err := newSolver(problem, *solver, *seed, len(resumed.Flights) > 0)
+	if err != nil {
+		fail(err)
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if *iterations > 0 {
+		// greedy and exact run to completion and are deterministic anyway
+		if b, ok := g.(fsp.Budgeted); ok {
+			b.SetIterations(*iterations)
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+	} else {
+		limit := problem.TimeLimit()
+		if *timeLimit > 0 {
+			limit = *timeLimit
+		}
+		// solvers get the time limit minus a safety margin and the grace
+		// period they have to exit in after cancellation
+		ctx, cancel = context.WithTimeout(context.Background(),
+			limit-time.Since(start_time)-45*time.Millisecond-grace)
+	}
+	defer cancel()
+	// SIGINT and SIGTERM stop the solvers early, the best tour so far is
+	// written as at the deadline, a second signal kills right away
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	caught := make(chan os.Signal, 1)
+	go func() {
+		sig := <-signals
+		signal.Stop(signals)
+		caught <- sig
+		cancel()
+	}()
+	c := fsp.NewComm(problem)
+	if *checkpoint != "" {
+		c.Checkpoint(*checkpoint, *ckEvery)
+	}
+	if len(resumed.Flights) > 0 {
+		fsp.WithSolver(c, "checkpoint").Send(resumed)
+	}
+	c.Run(ctx, name, g)
+	// the bound is only reported, it does not need to hold up the solvers,
+	// unless it proves that they are looking for nothing
+	bound := make(chan fsp.Money, 1)
+	go func() {
+		lb, ok := fsp.LowerBound(problem)
+		if !ok {
+			c.Infeasible()
+		}
+		bound <- lb
+	}()
+	if !c.Wait(ctx, grace) && !*quiet {
+		fmt.Fprintln(os.Stderr, "solver did not exit within", grace)
+	}
+
+	if err := c.Flush(); err != nil && !*quiet {
+		fmt.Fprintln(os.Stderr, "checkpoint failed:", err)
+	}
+	var interrupted os.Signal
+	select {
+	case interrupted = <-caught:
+	default:
+	}
+
+	var lb fsp.Money
+	if *iterations > 0 && interrupted == nil {
+		// replayed output must not depend on timing
+		lb = <-bound
+	} else {
+		select {
+		case lb = <-bound:
+		case <-time.After(grace):
+		}
+	}
+	meta := c.Meta()
+	meta.Seed, meta.LowerBound = *seed, lb
+	if s := c.Current(); name == "exact" && len(s.Flights) > 0 {
+		// the exact solver proves its tour optimal
+		meta.LowerBound = s.TotalCost
+	}
+	if *iterations > 0 {
+		// wall clock would make replayed output differ
+		meta.Elapsed = 0
+	}
+	if err := fsp.WriteSolution(out, *format, problem, c.Current(), meta, *currency); err != nil {
+		fail(err)
+	}
+	if meta.Rejected > 0 && !*quiet {
+		fmt.Fprintln(os.Stderr, "rejected", meta.Rejected, "invalid tours")
+	}
+	status := 0
+	switch meta.Outcome {
+	case fsp.Found:
+		if !*quiet {
+			for _, v := range fsp.Validate(problem, c.Current()) {
+				fmt.Fprintln(os.Stderr, v)
+			}
+		}
+	case fsp.Infeasible:
+		status = exitInfeasible
+		if !*quiet {
+			fmt.Fprintln(os.Stderr, "no tour exists")
+			causes := fsp.Diagnose(problem)
+			for _, c := range causes {
+				fmt.Fprintln(os.Stderr, c)
+			}
+			if len(causes) == 0 {
+				fmt.Fprintln(os.Stderr, "every way through the flights revisits an area")
+			}
+		}
+	case fsp.NotFound:
+		status = exitNotFound
+		if !*quiet {
+			fmt.Fprintln(os.Stderr, "no tour found")
+		}
+	}
+
+	if !*quiet {
+		fmt.Fprintln(os.Stderr, "Seed", *seed)
+		if s := c.Current(); meta.LowerBound > 0 && len(s.Flights) > 0 {
+			fmt.Fprintf(os.Stderr, "Lower bound %v, gap %.1f%%\n", meta.LowerBound,
+				100*float64(s.TotalCost-meta.LowerBound)/float64(s.TotalCost))
+		}
+		fmt.Fprintln(os.Stderr, "Ending after", time.Since(start_time))
+	}
+	if sig, ok := interrupted.(syscall.Signal); ok {
+		if !*quiet {
+			fmt.Fprintln(os.Stderr, "interrupted by", sig)
+		}
+		status = exitSignaled + int(sig)
+	}
+	if status != 0 {
+		os.Exit(status)
+	}
+}